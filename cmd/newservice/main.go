@@ -0,0 +1,110 @@
+// Command newservice generates a ready-to-build microservice skeleton that uses
+// the latest go-microservice-base APIs, so new services start out consistent.
+//
+// Usage:
+//
+//	go run github.com/illmade-knight/go-microservice-base/cmd/newservice \
+//		-name keyservice -module github.com/acme/platform/keyservice -out ./keyservice
+package main
+
+import (
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// validName restricts service names to valid, lowercase Go package names.
+var validName = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+// params is the data passed to every template.
+type params struct {
+	Name   string // package and service name, e.g. "keyservice"
+	Module string // import path of the generated package
+}
+
+// outputs maps each template to the file it generates, relative to the output directory.
+func outputs(p params) map[string]string {
+	return map[string]string{
+		"config.go.tmpl":        "config.go",
+		"service.go.tmpl":       "service.go",
+		"handlers.go.tmpl":      "handlers.go",
+		"handlers_test.go.tmpl": "handlers_test.go",
+		"main.go.tmpl":          filepath.Join("cmd", p.Name, "main.go"),
+		"config.yaml.tmpl":      "config.yaml",
+	}
+}
+
+func main() {
+	name := flag.String("name", "", "service name; must be a valid lowercase Go package name (required)")
+	module := flag.String("module", "", "import path of the generated package (required)")
+	out := flag.String("out", "", "output directory (defaults to ./<name>)")
+	flag.Parse()
+
+	if *out == "" {
+		*out = *name
+	}
+	if err := generate(params{Name: *name, Module: *module}, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "newservice: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Generated %s in %s.\nNext steps:\n  go mod tidy\n  go test ./...\n  go run %s/cmd/%s -config %s\n",
+		*name, *out, *module, *name, filepath.Join(*out, "config.yaml"))
+}
+
+// generate renders all templates into dir, refusing to overwrite existing files.
+// Every file is rendered and every target checked before anything is written, so a
+// failed run never leaves a partial skeleton behind.
+func generate(p params, dir string) error {
+	if !validName.MatchString(p.Name) {
+		return fmt.Errorf("invalid service name %q: use lowercase letters and digits only", p.Name)
+	}
+	if p.Module == "" {
+		return errors.New("-module is required")
+	}
+
+	tmpl, err := template.ParseFS(templates, "templates/*.tmpl")
+	if err != nil {
+		return fmt.Errorf("failed to parse templates: %w", err)
+	}
+
+	files := make(map[string][]byte)
+	for tmplName, rel := range outputs(p) {
+		path := filepath.Join(dir, rel)
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("refusing to overwrite existing file %s", path)
+		}
+
+		var sb strings.Builder
+		if err := tmpl.ExecuteTemplate(&sb, tmplName, p); err != nil {
+			return fmt.Errorf("failed to render %s: %w", tmplName, err)
+		}
+		content := []byte(sb.String())
+		if strings.HasSuffix(rel, ".go") {
+			if content, err = format.Source(content); err != nil {
+				return fmt.Errorf("generated invalid Go for %s: %w", rel, err)
+			}
+		}
+		files[path] = content
+	}
+
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
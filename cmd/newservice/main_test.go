@@ -0,0 +1,85 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	p := params{Name: "keyservice", Module: "github.com/acme/platform/keyservice"}
+
+	require.NoError(t, generate(p, dir))
+
+	for _, rel := range outputs(p) {
+		path := filepath.Join(dir, rel)
+		content, err := os.ReadFile(path)
+		require.NoError(t, err, "expected %s to be generated", rel)
+
+		if strings.HasSuffix(rel, ".go") {
+			_, err := parser.ParseFile(token.NewFileSet(), path, content, parser.AllErrors)
+			assert.NoError(t, err, "generated file %s should be valid Go", rel)
+		}
+	}
+
+	main, err := os.ReadFile(filepath.Join(dir, "cmd", "keyservice", "main.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(main), "microservice.Bootstrap(keyservice.New)")
+
+	t.Run("Refuses to overwrite", func(t *testing.T) {
+		assert.ErrorContains(t, generate(p, dir), "refusing to overwrite")
+	})
+
+	t.Run("Writes nothing when any file exists", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("keep: me\n"), 0o644))
+
+		assert.ErrorContains(t, generate(p, dir), "refusing to overwrite")
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "no other file should have been written")
+	})
+
+	t.Run("Rejects invalid names", func(t *testing.T) {
+		assert.Error(t, generate(params{Name: "Key-Service", Module: p.Module}, t.TempDir()))
+	})
+}
+
+// TestGenerate_Builds compiles and vets a generated service against this checkout,
+// catching template code that parses but does not type-check.
+func TestGenerate_Builds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a generated module")
+	}
+	root, err := filepath.Abs(filepath.Join("..", ".."))
+	require.NoError(t, err)
+	goSum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	p := params{Name: "keyservice", Module: "example.com/keyservice"}
+	require.NoError(t, generate(p, dir))
+
+	goMod := "module " + p.Module + "\n\ngo 1.24\n\n" +
+		"require github.com/illmade-knight/go-microservice-base v0.0.0\n\n" +
+		"replace github.com/illmade-knight/go-microservice-base => " + root + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.sum"), goSum, 0o644))
+
+	for _, args := range [][]string{{"build", "./..."}, {"vet", "./..."}} {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		// -mod=mod resolves the generated module's requirements from the replaced checkout.
+		cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, "go %s failed:\n%s", strings.Join(args, " "), out)
+	}
+}
@@ -0,0 +1,28 @@
+// Package metrics holds Prometheus helpers shared by the library's packages.
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Register registers c with reg, returning the already-registered collector
+// if an identical one exists. This lets constructors be called more than once
+// (e.g. per route group or in tests) without panicking on duplicate registration.
+// A nil reg means the global registry served by BaseServer's /metrics endpoint.
+func Register[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	if err := reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
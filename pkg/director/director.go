@@ -0,0 +1,174 @@
+// Package director is a client for the service director, which keeps track of the
+// running instances of the services making up a dataflow and serves their configuration.
+//
+// The director exposes a small JSON API below its base URL:
+//
+//	POST   /v1/services/{service}/instances                     register an instance (Registration)
+//	PUT    /v1/services/{service}/instances/{id}/heartbeat      report liveness ({"ready": bool})
+//	DELETE /v1/services/{service}/instances/{id}                deregister an instance
+//	GET    /v1/dataflows/{dataflow}/services/{service}/config   fetch the service's dataflow configuration
+//
+// Most services do not use this package directly: microservice.WithDirector ties
+// registration, heartbeats and deregistration to the BaseServer lifecycle.
+package director
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNotRegistered is returned by Heartbeat when the director does not know the
+// instance, e.g. after the director restarted; the instance should register again.
+var ErrNotRegistered = errors.New("director: instance is not registered")
+
+// Config configures a Client.
+type Config struct {
+	// URL is the base URL of the service director.
+	URL string
+	// ServiceName and DataflowName identify the service.
+	ServiceName  string
+	DataflowName string
+	// InstanceID identifies this instance. Defaults to the hostname plus a random suffix.
+	InstanceID string
+	// HTTPClient sends the requests, e.g. an outbound.NewClient attaching ID tokens.
+	// Defaults to a client with a 10s timeout.
+	HTTPClient *http.Client
+}
+
+// Registration describes a service instance to the director.
+type Registration struct {
+	ServiceName  string `json:"service_name"`
+	DataflowName string `json:"dataflow_name,omitempty"`
+	InstanceID   string `json:"instance_id"`
+	Version      string `json:"version,omitempty"`
+	// Address is where the instance can be reached, e.g. "10.0.0.5:8080" or a Cloud Run URL.
+	Address string `json:"address"`
+}
+
+// Client talks to the service director on behalf of one service instance.
+type Client struct {
+	cfg Config
+}
+
+// NewClient returns a client for the director at cfg.URL.
+func NewClient(cfg Config) *Client {
+	cfg.URL = strings.TrimSuffix(cfg.URL, "/")
+	if cfg.InstanceID == "" {
+		cfg.InstanceID = defaultInstanceID()
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Client{cfg: cfg}
+}
+
+// InstanceID returns the ID this instance registers under.
+func (c *Client) InstanceID() string {
+	return c.cfg.InstanceID
+}
+
+// Register announces the instance, reachable at address, running the given version.
+func (c *Client) Register(ctx context.Context, address, version string) error {
+	reg := Registration{
+		ServiceName:  c.cfg.ServiceName,
+		DataflowName: c.cfg.DataflowName,
+		InstanceID:   c.cfg.InstanceID,
+		Version:      version,
+		Address:      address,
+	}
+	_, err := c.do(ctx, http.MethodPost, c.instancesPath(), reg)
+	return err
+}
+
+// Heartbeat reports that the instance is alive and whether it is ready for traffic.
+func (c *Client) Heartbeat(ctx context.Context, ready bool) error {
+	_, err := c.do(ctx, http.MethodPut, c.instancesPath()+"/"+url.PathEscape(c.cfg.InstanceID)+"/heartbeat",
+		map[string]bool{"ready": ready})
+	return err
+}
+
+// Deregister removes the instance from the director. An unknown instance is not an error.
+func (c *Client) Deregister(ctx context.Context) error {
+	_, err := c.do(ctx, http.MethodDelete, c.instancesPath()+"/"+url.PathEscape(c.cfg.InstanceID), nil)
+	if errors.Is(err, ErrNotRegistered) {
+		return nil
+	}
+	return err
+}
+
+// DataflowConfig fetches the service's configuration within its dataflow as raw JSON.
+func (c *Client) DataflowConfig(ctx context.Context) (json.RawMessage, error) {
+	body, err := c.do(ctx, http.MethodGet, "/v1/dataflows/"+url.PathEscape(c.cfg.DataflowName)+
+		"/services/"+url.PathEscape(c.cfg.ServiceName)+"/config", nil)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(body) {
+		return nil, errors.New("director: dataflow configuration is not valid JSON")
+	}
+	return body, nil
+}
+
+func (c *Client) instancesPath() string {
+	return "/v1/services/" + url.PathEscape(c.cfg.ServiceName) + "/instances"
+}
+
+// do sends a JSON request and returns the response body of a 2xx response.
+func (c *Client) do(ctx context.Context, method, path string, payload any) ([]byte, error) {
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.cfg.URL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("director: %s %s failed: %w", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("director: failed to read response: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound && (method == http.MethodPut || method == http.MethodDelete):
+		return nil, ErrNotRegistered
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, fmt.Errorf("director: %s %s returned status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
+
+// defaultInstanceID combines the hostname with a random suffix, so restarts on the
+// same host register as new instances.
+func defaultInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "instance"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return host + "-" + hex.EncodeToString(suffix)
+}
@@ -0,0 +1,69 @@
+package director_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/director"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	var registered director.Registration
+	var heartbeat map[string]bool
+	known := true
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/services/ingest/instances", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&registered)
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("PUT /v1/services/ingest/instances/i-1/heartbeat", func(w http.ResponseWriter, r *http.Request) {
+		if !known {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&heartbeat)
+	})
+	mux.HandleFunc("DELETE /v1/services/ingest/instances/i-1", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("GET /v1/dataflows/telemetry/services/ingest/config", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"topic":"readings"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := director.NewClient(director.Config{
+		URL: server.URL + "/", ServiceName: "ingest", DataflowName: "telemetry", InstanceID: "i-1",
+	})
+	ctx := context.Background()
+
+	require.NoError(t, client.Register(ctx, "10.0.0.5:8080", "v1.2.3"))
+	assert.Equal(t, director.Registration{
+		ServiceName: "ingest", DataflowName: "telemetry", InstanceID: "i-1", Version: "v1.2.3", Address: "10.0.0.5:8080",
+	}, registered)
+
+	require.NoError(t, client.Heartbeat(ctx, true))
+	assert.Equal(t, map[string]bool{"ready": true}, heartbeat)
+
+	known = false
+	assert.ErrorIs(t, client.Heartbeat(ctx, true), director.ErrNotRegistered)
+
+	cfg, err := client.DataflowConfig(ctx)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"topic":"readings"}`, string(cfg))
+
+	assert.NoError(t, client.Deregister(ctx), "deregistering an unknown instance is not an error")
+}
+
+func TestNewClient_DefaultInstanceID(t *testing.T) {
+	a := director.NewClient(director.Config{URL: "http://director"})
+	b := director.NewClient(director.Config{URL: "http://director"})
+	assert.NotEmpty(t, a.InstanceID())
+	assert.NotEqual(t, a.InstanceID(), b.InstanceID())
+}
@@ -0,0 +1,17 @@
+package microservice
+
+import (
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+)
+
+// WithRequestLogging installs middleware.NewRequestLogger on the server using the
+// server's logger, so every service gets uniform access logs. The probe and metrics
+// endpoints are excluded; further options (e.g. middleware.WithSampling) are appended.
+func WithRequestLogging(opts ...middleware.RequestLoggerOption) Option {
+	return func(s *BaseServer) {
+		opts = append([]middleware.RequestLoggerOption{
+			middleware.WithExcludedPaths("/healthz", "/readyz", "/metrics"),
+		}, opts...)
+		s.httpServer.Handler = middleware.NewRequestLogger(s.Logger, opts...)(s.httpServer.Handler)
+	}
+}
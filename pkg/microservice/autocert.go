@@ -0,0 +1,181 @@
+package microservice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// AutocertConfig configures automatic TLS certificates via ACME (e.g. Let's Encrypt).
+// It is intended for smaller deployments that are not fronted by a managed load balancer.
+type AutocertConfig struct {
+	// Domains is the allowlist of host names that certificates may be requested for.
+	Domains []string
+	// CacheDir is a local directory used to persist certificates between restarts.
+	// It is ignored if Cache is set. Without either, certificates are requested again
+	// on every start, which quickly runs into the ACME provider's rate limits.
+	CacheDir string
+	// Cache overrides certificate storage, e.g. a GCSCertCache for stateless containers.
+	Cache autocert.Cache
+	// Email is an optional contact address registered with the ACME provider.
+	Email string
+	// ChallengeAddr is the listen address for the HTTP-01 challenge handler. Defaults to ":80".
+	ChallengeAddr string
+}
+
+// WithAutocert switches the server to TLS using certificates obtained automatically via ACME.
+// The main listener serves HTTPS on HTTPPort, and an HTTP-01 challenge handler is mounted
+// on ChallengeAddr; any other plain HTTP request on that address is redirected to HTTPS.
+func WithAutocert(cfg AutocertConfig) Option {
+	return func(s *BaseServer) {
+		if len(cfg.Domains) == 0 {
+			s.Logger.Warn().Msg("Autocert enabled without any domains; all certificate requests will be rejected.")
+		}
+
+		cache := cfg.Cache
+		if cache == nil && cfg.CacheDir != "" {
+			cache = autocert.DirCache(cfg.CacheDir)
+		}
+		if cache == nil {
+			s.Logger.Warn().Msg("Autocert enabled without a certificate cache; certificates will be requested again on every start.")
+		}
+
+		s.certManager = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+			Cache:      cache,
+			Email:      cfg.Email,
+		}
+
+		challengeAddr := cfg.ChallengeAddr
+		if challengeAddr == "" {
+			challengeAddr = ":80"
+		}
+		s.challengeServer = &http.Server{
+			Addr:    challengeAddr,
+			Handler: s.certManager.HTTPHandler(nil),
+		}
+	}
+}
+
+// serveChallenges runs the HTTP-01 challenge server until it is shut down.
+func (s *BaseServer) serveChallenges() {
+	s.Logger.Info().Str("address", s.challengeServer.Addr).Msg("ACME challenge server starting to listen")
+	if err := s.challengeServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.Logger.Error().Err(err).Msg("ACME challenge server failed")
+	}
+}
+
+// defaultGCSEndpoint is the public Google Cloud Storage JSON API endpoint.
+const defaultGCSEndpoint = "https://storage.googleapis.com"
+
+// GCSCertCache is an autocert.Cache backed by a Google Cloud Storage bucket.
+// It talks to the GCS JSON API directly so that services don't need to pull in
+// the full storage SDK just to persist a handful of certificates.
+type GCSCertCache struct {
+	// Client must attach credentials with storage read/write scope,
+	// e.g. one created with golang.org/x/oauth2/google.DefaultClient.
+	Client *http.Client
+	// Bucket is the name of the bucket holding the certificates.
+	Bucket string
+	// Prefix is prepended to every object name, e.g. "certs/".
+	Prefix string
+	// Endpoint overrides the API endpoint, mainly for tests. Defaults to the public GCS endpoint.
+	Endpoint string
+}
+
+// NewGCSCertCache creates a GCSCertCache for the given bucket and object prefix.
+func NewGCSCertCache(client *http.Client, bucket, prefix string) *GCSCertCache {
+	return &GCSCertCache{Client: client, Bucket: bucket, Prefix: prefix}
+}
+
+// Get reads a certificate data from the bucket, returning autocert.ErrCacheMiss if it does not exist.
+func (c *GCSCertCache) Get(ctx context.Context, key string) ([]byte, error) {
+	u := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media",
+		c.endpoint(), url.PathEscape(c.Bucket), url.PathEscape(c.Prefix+key))
+	resp, err := c.do(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, autocert.ErrCacheMiss
+	default:
+		return nil, fmt.Errorf("failed to read %q from bucket %s: status %d", key, c.Bucket, resp.StatusCode)
+	}
+}
+
+// Put writes certificate data to the bucket.
+func (c *GCSCertCache) Put(ctx context.Context, key string, data []byte) error {
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+		c.endpoint(), url.PathEscape(c.Bucket), url.QueryEscape(c.Prefix+key))
+	resp, err := c.do(ctx, http.MethodPost, u, data)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to write %q to bucket %s: status %d", key, c.Bucket, resp.StatusCode)
+	}
+	return nil
+}
+
+// Delete removes certificate data from the bucket. Missing objects are not an error.
+func (c *GCSCertCache) Delete(ctx context.Context, key string) error {
+	u := fmt.Sprintf("%s/storage/v1/b/%s/o/%s",
+		c.endpoint(), url.PathEscape(c.Bucket), url.PathEscape(c.Prefix+key))
+	resp, err := c.do(ctx, http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete %q from bucket %s: status %d", key, c.Bucket, resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *GCSCertCache) endpoint() string {
+	if c.Endpoint != "" {
+		return c.Endpoint
+	}
+	return defaultGCSEndpoint
+}
+
+func (c *GCSCertCache) do(ctx context.Context, method, u string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GCS request failed: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,133 @@
+package microservice_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// newFakeGCSServer emulates the subset of the GCS JSON API used by GCSCertCache.
+func newFakeGCSServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/upload/storage/v1/b/test-bucket/o"):
+			data, _ := io.ReadAll(r.Body)
+			objects[r.URL.Query().Get("name")] = data
+			w.WriteHeader(http.StatusOK)
+		case strings.HasPrefix(r.URL.Path, "/storage/v1/b/test-bucket/o/"):
+			name := strings.TrimPrefix(r.URL.Path, "/storage/v1/b/test-bucket/o/")
+			data, ok := objects[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if r.Method == http.MethodDelete {
+				delete(objects, name)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			_, _ = w.Write(data)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+}
+
+func TestGCSCertCache(t *testing.T) {
+	gcs := newFakeGCSServer(t)
+	defer gcs.Close()
+
+	cache := microservice.NewGCSCertCache(gcs.Client(), "test-bucket", "certs/")
+	cache.Endpoint = gcs.URL
+	ctx := context.Background()
+
+	// 1. A missing key must report a cache miss so autocert requests a new certificate.
+	_, err := cache.Get(ctx, "example.com")
+	require.ErrorIs(t, err, autocert.ErrCacheMiss)
+
+	// 2. Round-trip a value.
+	require.NoError(t, cache.Put(ctx, "example.com", []byte("cert-data")))
+	data, err := cache.Get(ctx, "example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "cert-data", string(data))
+
+	// 3. Delete it, then delete again (idempotent).
+	require.NoError(t, cache.Delete(ctx, "example.com"))
+	require.NoError(t, cache.Delete(ctx, "example.com"))
+	_, err = cache.Get(ctx, "example.com")
+	require.ErrorIs(t, err, autocert.ErrCacheMiss)
+}
+
+func TestWithAutocert(t *testing.T) {
+	t.Run("Missing cache is reported", func(t *testing.T) {
+		var logs bytes.Buffer
+		microservice.NewBaseServer(zerolog.New(&logs), ":0", microservice.WithAutocert(microservice.AutocertConfig{
+			Domains: []string{"api.example.com"},
+		}))
+		assert.Contains(t, logs.String(), "without a certificate cache")
+	})
+
+	t.Run("Challenge server and TLS listener follow Start and Shutdown", func(t *testing.T) {
+		// Reserve a free port for the challenge server, which listens on a fixed address.
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		challengeAddr := l.Addr().String()
+		require.NoError(t, l.Close())
+
+		server := microservice.NewBaseServer(zerolog.Nop(), ":0", microservice.WithAutocert(microservice.AutocertConfig{
+			Domains:       []string{"api.example.com"},
+			Cache:         autocert.DirCache(t.TempDir()),
+			ChallengeAddr: challengeAddr,
+		}))
+		readyChan := make(chan struct{})
+		server.SetReadyChannel(readyChan)
+		go func() { _ = server.Start() }()
+		<-readyChan
+
+		// Plain HTTP on the challenge address is redirected to HTTPS.
+		client := &http.Client{
+			Transport:     &http.Transport{DisableKeepAlives: true},
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+		var resp *http.Response
+		require.Eventually(t, func() bool {
+			resp, err = client.Get("http://" + challengeAddr + "/items")
+			return err == nil
+		}, 2*time.Second, 10*time.Millisecond)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusFound, resp.StatusCode)
+		assert.True(t, strings.HasPrefix(resp.Header.Get("Location"), "https://"))
+
+		// The main listener speaks TLS and refuses hosts outside the allowlist.
+		_, err = tls.Dial("tcp", "127.0.0.1"+server.GetHTTPPort(), &tls.Config{ServerName: "evil.example.com"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "remote error")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		require.NoError(t, server.Shutdown(ctx))
+		_, err = client.Get("http://" + challengeAddr + "/items")
+		assert.Error(t, err, "the challenge server should stop with the server")
+	})
+}
@@ -0,0 +1,143 @@
+package microservice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+)
+
+// ExitCodeBackgroundFailure is the process exit code used by FailExit, distinct from
+// the generic exit code 1 so orchestrators and alerts can tell the two apart.
+const ExitCodeBackgroundFailure = 3
+
+// FailureAction is what the server does once a background component has failed repeatedly.
+type FailureAction int
+
+const (
+	// FailRestart keeps restarting the component and only logs. This is the default.
+	FailRestart FailureAction = iota
+	// FailNotReady permanently marks the server as not ready, so the load balancer
+	// stops routing to it and the orchestrator can replace it.
+	FailNotReady
+	// FailExit terminates the process with ExitCodeBackgroundFailure.
+	FailExit
+)
+
+// BackgroundPolicy controls how failures of background components are handled.
+type BackgroundPolicy struct {
+	Action FailureAction
+	// MaxFailures is the number of consecutive failures (errors or panics) that
+	// triggers Action. Defaults to 3.
+	MaxFailures int
+	// RestartDelay is the pause before a failed component is restarted. Defaults to 1s.
+	RestartDelay time.Duration
+	// ResetAfter resets the failure count when a run lasts at least this long. Defaults to 1m.
+	ResetAfter time.Duration
+}
+
+// exitProcess is replaced in tests.
+var exitProcess = os.Exit
+
+// WithBackgroundPolicy sets the failure policy for components started with RunBackground.
+// Without it a dead consumer leaves the HTTP server reporting healthy; FailNotReady or
+// FailExit surface the failure instead.
+func WithBackgroundPolicy(policy BackgroundPolicy) Option {
+	return func(s *BaseServer) {
+		s.backgroundPolicy = policy
+	}
+}
+
+// RunBackground runs a long-lived component, such as a Pub/Sub consumer, in its own
+// goroutine. fn should block until ctx is cancelled; returning nil ends the component,
+// while an error or panic counts as a failure and the component is restarted.
+// ctx is cancelled when Shutdown begins, and Shutdown waits for fn to return.
+func (s *BaseServer) RunBackground(name string, fn func(ctx context.Context) error) {
+	s.backgroundWG.Add(1)
+	go func() {
+		defer s.backgroundWG.Done()
+		s.superviseBackground(name, fn)
+	}()
+}
+
+// superviseBackground restarts fn after failures and applies the policy once they repeat.
+func (s *BaseServer) superviseBackground(name string, fn func(ctx context.Context) error) {
+	policy := s.backgroundPolicy
+	if policy.MaxFailures <= 0 {
+		policy.MaxFailures = 3
+	}
+	if policy.RestartDelay <= 0 {
+		policy.RestartDelay = time.Second
+	}
+	if policy.ResetAfter <= 0 {
+		policy.ResetAfter = time.Minute
+	}
+
+	ctx := s.backgroundCtx
+	failures := 0
+	for {
+		started := time.Now()
+		err := runRecovered(ctx, fn)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			s.Logger.Info().Str("component", name).Msg("Background component finished")
+			return
+		}
+
+		if time.Since(started) >= policy.ResetAfter {
+			failures = 0
+		}
+		failures++
+		s.Logger.Error().Err(err).Str("component", name).Int("consecutive_failures", failures).
+			Msg("Background component failed")
+
+		if failures >= policy.MaxFailures {
+			switch policy.Action {
+			case FailNotReady:
+				s.Logger.Error().Str("component", name).Msg("Background component keeps failing, marking service as not ready")
+				s.backgroundFailed.Store(true)
+				s.SetReady(false)
+				return
+			case FailExit:
+				s.Logger.Error().Str("component", name).Int("exit_code", ExitCodeBackgroundFailure).
+					Msg("Background component keeps failing, exiting")
+				exitProcess(ExitCodeBackgroundFailure)
+				return
+			}
+		}
+
+		select {
+		case <-time.After(policy.RestartDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runRecovered calls fn, converting a panic into an error.
+func runRecovered(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return fn(ctx)
+}
+
+// stopBackground cancels background components and waits for them or ctx.
+func (s *BaseServer) stopBackground(ctx context.Context) {
+	s.backgroundCancel()
+	done := make(chan struct{})
+	go func() {
+		s.backgroundWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.Logger.Warn().Msg("Background components did not stop before the shutdown deadline")
+	}
+}
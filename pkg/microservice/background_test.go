@@ -0,0 +1,93 @@
+package microservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func readyzStatus(s *BaseServer) int {
+	rec := httptest.NewRecorder()
+	s.readyzHandler(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	return rec.Code
+}
+
+func TestRunBackground_FailNotReady(t *testing.T) {
+	server := NewBaseServer(zerolog.Nop(), ":0", WithBackgroundPolicy(BackgroundPolicy{
+		Action:       FailNotReady,
+		MaxFailures:  3,
+		RestartDelay: time.Millisecond,
+	}), WithGRPC(GRPCConfig{Port: "0"}))
+	server.SetReady(true)
+
+	var runs atomic.Int32
+	server.RunBackground("consumer", func(ctx context.Context) error {
+		if runs.Add(1)%2 == 0 {
+			panic("poison message")
+		}
+		return errors.New("subscription lost")
+	})
+
+	require.Eventually(t, func() bool { return readyzStatus(server) == http.StatusServiceUnavailable },
+		time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(3), runs.Load(), "panics and errors both count as failures")
+
+	// The component tripped the policy; SetReady cannot mask it.
+	server.SetReady(true)
+	assert.Equal(t, http.StatusServiceUnavailable, readyzStatus(server))
+	resp, err := server.grpcHealth.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status, "gRPC health must agree with /readyz")
+}
+
+func TestRunBackground_FailExit(t *testing.T) {
+	exitCode := make(chan int, 1)
+	original := exitProcess
+	exitProcess = func(code int) { exitCode <- code }
+	t.Cleanup(func() { exitProcess = original })
+
+	server := NewBaseServer(zerolog.Nop(), ":0", WithBackgroundPolicy(BackgroundPolicy{
+		Action:       FailExit,
+		MaxFailures:  2,
+		RestartDelay: time.Millisecond,
+	}))
+	server.RunBackground("consumer", func(ctx context.Context) error {
+		return errors.New("fatal")
+	})
+
+	select {
+	case code := <-exitCode:
+		assert.Equal(t, ExitCodeBackgroundFailure, code)
+	case <-time.After(time.Second):
+		t.Fatal("process was not exited")
+	}
+}
+
+func TestRunBackground_StoppedByShutdown(t *testing.T) {
+	server := NewBaseServer(zerolog.Nop(), ":0")
+	stopped := make(chan struct{})
+	server.RunBackground("consumer", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(stopped)
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = server.Shutdown(ctx)
+
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("Shutdown returned before the background component stopped")
+	}
+}
@@ -3,6 +3,7 @@ package microservice
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -10,9 +11,16 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/netutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 )
 
 // BaseConfig holds common configuration fields for all services.
@@ -25,6 +33,14 @@ type BaseConfig struct {
 	ServiceName        string `yaml:"service_name"`
 	DataflowName       string `yaml:"dataflow_name"`
 	ServiceDirectorURL string `yaml:"service_director_url"`
+
+	// RequestTimeout is the default deadline attached to every request, e.g. "30s". Zero disables it.
+	RequestTimeout   time.Duration    `yaml:"request_timeout"`
+	ConnectionLimits ConnectionLimits `yaml:"connection_limits"`
+	Tracing          TracingConfig    `yaml:"tracing"`
+	GRPC             GRPCConfig       `yaml:"grpc"`
+	TLS              TLSConfig        `yaml:"tls"`
+	Director         DirectorConfig   `yaml:"director"` // used when ServiceDirectorURL is set
 }
 
 // Service defines the common interface for all microservices.
@@ -46,10 +62,49 @@ type BaseServer struct {
 	readyChan  chan struct{}
 	// ADDED: Atomically controlled readiness state.
 	isReady *atomic.Value
+
+	// certManager and challengeServer are only set in autocert mode.
+	certManager     *autocert.Manager
+	challengeServer *http.Server
+	// certReloader is only set when serving TLS from files; see tls.go.
+	certReloader *certReloader
+
+	// drainers are notified at the start of Shutdown.
+	drainers []namedDrainer
+
+	// maxConnections limits concurrently accepted connections when > 0.
+	maxConnections int
+
+	// disablePanicRecovery is set by WithoutPanicRecovery; see recovery.go.
+	disablePanicRecovery bool
+
+	// director is only set when WithDirector is used; see director.go.
+	director *directorState
+
+	// Background components started with RunBackground; see background.go.
+	backgroundPolicy BackgroundPolicy
+	backgroundCtx    context.Context
+	backgroundCancel context.CancelFunc
+	backgroundWG     sync.WaitGroup
+	backgroundFailed atomic.Bool
+
+	// readinessChecks are aggregated by /readyz; see readiness.go.
+	readinessChecks  []namedCheck
+	readinessTimeout time.Duration
+
+	// tracerProvider is set by WithTracing and flushed on Shutdown.
+	tracerProvider *sdktrace.TracerProvider
+
+	// The gRPC server is only set when WithGRPC is used; see grpc.go.
+	grpcServer     *grpc.Server
+	grpcHealth     *health.Server
+	grpcAddr       string
+	actualGRPCAddr string
 }
 
 // NewBaseServer creates and initializes a new BaseServer.
-func NewBaseServer(logger zerolog.Logger, httpPort string) *BaseServer {
+// Optional behaviour (e.g. WithAutocert) is enabled by passing Options.
+func NewBaseServer(logger zerolog.Logger, httpPort string, opts ...Option) *BaseServer {
 	mux := http.NewServeMux()
 
 	listenAddr := httpPort
@@ -68,14 +123,22 @@ func NewBaseServer(logger zerolog.Logger, httpPort string) *BaseServer {
 		HTTPPort: listenAddr,
 		mux:      mux,
 		isReady:  isReady,
+
+		readinessTimeout: defaultReadinessTimeout,
 	}
 	s.httpServer = &http.Server{
-		Addr:    listenAddr,
-		Handler: mux,
+		Addr: listenAddr,
+		// CaptureRoute keeps the matched pattern visible to observability options.
+		Handler: s.recoverPanics(middleware.CaptureRoute(mux)),
 	}
+	s.backgroundCtx, s.backgroundCancel = context.WithCancel(context.Background())
 
 	// Register all default handlers
 	s.registerDefaultHandlers()
+
+	for _, opt := range opts {
+		opt(s)
+	}
 	return s
 }
 
@@ -93,7 +156,12 @@ func (s *BaseServer) SetReadyChannel(ch chan struct{}) {
 // SetReady allows the consuming service to signal that it is ready to serve traffic.
 // This is thread-safe.
 func (s *BaseServer) SetReady(ready bool) {
+	if s.director != nil && !s.director.allowReady(ready) {
+		s.Logger.Info().Msg("Readiness deferred until the dataflow configuration has been fetched.")
+		return
+	}
 	s.isReady.Store(ready)
+	s.setGRPCServing(ready)
 	if ready {
 		s.Logger.Info().Msg("Service has been marked as READY.")
 	} else {
@@ -113,6 +181,28 @@ func (s *BaseServer) Start() error {
 	s.actualAddr = listener.Addr().String()
 	s.mu.Unlock()
 
+	if s.maxConnections > 0 {
+		listener = netutil.LimitListener(listener, s.maxConnections)
+	}
+	tlsConfig, err := s.prepareTLS()
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	if s.certManager != nil {
+		listener = tls.NewListener(listener, s.certManager.TLSConfig())
+		go s.serveChallenges()
+	}
+	if err := s.startGRPC(); err != nil {
+		_ = listener.Close()
+		return err
+	}
+
+	s.startDirector()
+
 	s.Logger.Info().Str("address", s.actualAddr).Msg("HTTP server starting to listen")
 
 	if s.readyChan != nil {
@@ -129,8 +219,26 @@ func (s *BaseServer) Start() error {
 }
 
 // Shutdown gracefully stops the HTTP server.
+// Registered ConnectionDrainers are notified first so long-lived connections
+// can be closed instead of holding the shutdown open until ctx expires.
 func (s *BaseServer) Shutdown(ctx context.Context) error {
 	s.Logger.Info().Msg("Shutting down HTTP server...")
+	// Deregister first so the director stops routing to this instance while it drains.
+	s.stopDirector(ctx)
+	defer s.shutdownTracing(ctx)
+	defer s.stopBackground(ctx)
+	drained := s.drainConnections(ctx)
+	defer func() {
+		<-drained
+		s.logRemainingConnections()
+	}()
+
+	s.stopGRPC(ctx)
+	if s.challengeServer != nil {
+		if err := s.challengeServer.Shutdown(ctx); err != nil {
+			s.Logger.Error().Err(err).Msg("Error during ACME challenge server shutdown.")
+		}
+	}
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		s.Logger.Error().Err(err).Msg("Error during HTTP server shutdown.")
 		return err
@@ -162,9 +270,17 @@ func (s *BaseServer) healthzHandler(w http.ResponseWriter, _ *http.Request) {
 }
 
 // readyzHandler is the readiness probe. It returns 200 if the service is ready,
-// and 503 Service Unavailable otherwise.
-func (s *BaseServer) readyzHandler(w http.ResponseWriter, _ *http.Request) {
-	if s.isReady.Load().(bool) {
+// and 503 Service Unavailable otherwise. A background component that tripped
+// FailNotReady keeps the service not ready regardless of SetReady.
+// Once readiness checks are registered, the body is a JSON ReadinessReport.
+func (s *BaseServer) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	ready, results := s.readiness(r.Context())
+	if results != nil {
+		s.writeReadinessReport(w, ready, results)
+		return
+	}
+
+	if ready {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("READY"))
 		return
@@ -0,0 +1,80 @@
+package microservice
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog"
+)
+
+// Bootstrap is the standard main() for a microservice:
+//
+//	func main() {
+//		microservice.Bootstrap(keyservice.New)
+//	}
+//
+// It parses the -config, -port and -log-level flags, loads and validates the config file
+// into T with LoadConfig, sets up a zerolog logger, creates the service, and runs it with
+// Run until SIGINT or SIGTERM, at which point the service is shut down gracefully. Flags
+// take precedence over the environment, which takes precedence over the file. Service
+// configs should embed BaseConfig with the `yaml:",inline"` tag so its fields sit at the
+// top level of the file.
+//
+// Bootstrap exits the process with status 1 if any step fails.
+func Bootstrap[T any](newService func(cfg T, logger zerolog.Logger) (Service, error)) {
+	if err := bootstrap(context.Background(), os.Args, newService); err != nil {
+		fmt.Fprintf(os.Stderr, "service exited with error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// bootstrap implements Bootstrap without exiting the process.
+func bootstrap[T any](ctx context.Context, args []string, newService func(cfg T, logger zerolog.Logger) (Service, error)) error {
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv("CONFIG_FILE"), "path to the YAML config file")
+	port := fs.String("port", "", "HTTP port, overrides config and PORT")
+	logLevel := fs.String("log-level", "", "log level, overrides config and LOG_LEVEL")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	cfg, err := LoadConfig[T](*configPath)
+	if err != nil {
+		return err
+	}
+
+	base := baseConfigOf(cfg)
+	if base != nil {
+		if *port != "" {
+			base.HTTPPort = *port
+		}
+		if *logLevel != "" {
+			base.LogLevel = *logLevel
+		}
+	}
+
+	logger := newLogger(base)
+	svc, err := newService(*cfg, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+	return Run(ctx, svc, WithRunLogger(logger))
+}
+
+// newLogger creates the service's root logger, defaulting to info level.
+func newLogger(base *BaseConfig) zerolog.Logger {
+	logger := zerolog.New(os.Stdout).With().Timestamp().Logger()
+	if base == nil {
+		return logger.Level(zerolog.InfoLevel)
+	}
+	if base.ServiceName != "" {
+		logger = logger.With().Str("service", base.ServiceName).Logger()
+	}
+	level, err := zerolog.ParseLevel(base.LogLevel)
+	if err != nil || base.LogLevel == "" {
+		level = zerolog.InfoLevel
+	}
+	return logger.Level(level)
+}
@@ -0,0 +1,78 @@
+package microservice
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testServiceConfig is a typical service config embedding BaseConfig.
+type testServiceConfig struct {
+	BaseConfig `yaml:",inline"`
+	TopicID    string `yaml:"topic_id"`
+}
+
+// fakeService records its lifecycle calls.
+type fakeService struct {
+	started  chan struct{}
+	shutdown bool
+}
+
+func (f *fakeService) Start(_ context.Context) error {
+	close(f.started)
+	return nil
+}
+
+func (f *fakeService) Shutdown(_ context.Context) error {
+	f.shutdown = true
+	return nil
+}
+
+func (f *fakeService) Mux() *http.ServeMux { return http.NewServeMux() }
+func (f *fakeService) GetHTTPPort() string { return ":0" }
+
+func TestBootstrap(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+log_level: debug
+http_port: "8080"
+project_id: file-project
+topic_id: events
+`), 0o600))
+	t.Setenv("PROJECT_ID", "env-project")
+	t.Setenv("PORT", "9090")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	svc := &fakeService{started: make(chan struct{})}
+	var received testServiceConfig
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- bootstrap(ctx, []string{"svc", "-config", configPath, "-port", "7070"},
+			func(cfg testServiceConfig, _ zerolog.Logger) (Service, error) {
+				received = cfg
+				return svc, nil
+			})
+	}()
+
+	select {
+	case <-svc.started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("service was never started")
+	}
+	cancel()
+	require.NoError(t, <-errCh)
+
+	assert.Equal(t, "events", received.TopicID, "service fields come from the file")
+	assert.Equal(t, "debug", received.LogLevel)
+	assert.Equal(t, "env-project", received.ProjectID, "environment overrides the file")
+	assert.Equal(t, "7070", received.HTTPPort, "flags override the environment")
+	assert.True(t, svc.shutdown, "service should be shut down on cancellation")
+}
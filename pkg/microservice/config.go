@@ -0,0 +1,138 @@
+package microservice
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Validator is implemented by configs that need checks beyond required fields.
+// LoadConfig calls Validate after environment overrides have been applied.
+type Validator interface {
+	Validate() error
+}
+
+// LoadConfig reads the YAML file at path into a new T, applies the standard environment
+// overrides (PORT, LOG_LEVEL, PROJECT_ID) to an embedded BaseConfig and validates the
+// result. An empty path skips the file, leaving configuration to the environment.
+//
+// Service configs embed BaseConfig with the `yaml:",inline"` tag so its fields sit at the
+// top level of the file. Fields tagged `required:"true"` must be non-zero; all missing
+// fields are reported together, by their YAML name:
+//
+//	type Config struct {
+//		microservice.BaseConfig `yaml:",inline"`
+//		TopicID string `yaml:"topic_id" required:"true"`
+//	}
+//
+// If *T implements Validator, its Validate method is called last.
+func LoadConfig[T any](path string) (*T, error) {
+	cfg := new(T)
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if base := baseConfigOf(cfg); base != nil {
+		applyEnvOverrides(base)
+	}
+
+	if missing := missingRequired(reflect.ValueOf(cfg).Elem(), ""); len(missing) > 0 {
+		return nil, fmt.Errorf("invalid config: missing required fields: %s", strings.Join(missing, ", "))
+	}
+	if v, ok := any(cfg).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+	return cfg, nil
+}
+
+// baseConfigOf returns the BaseConfig embedded in (or equal to) *cfg, or nil if there is none.
+func baseConfigOf(cfg any) *BaseConfig {
+	if base, ok := cfg.(*BaseConfig); ok {
+		return base
+	}
+	v := reflect.ValueOf(cfg).Elem()
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		// Interface panics on unexported fields, so only embedded, exported ones are inspected.
+		if field := t.Field(i); !field.Anonymous || !field.IsExported() {
+			continue
+		}
+		if base, ok := v.Field(i).Addr().Interface().(*BaseConfig); ok {
+			return base
+		}
+	}
+	return nil
+}
+
+// applyEnvOverrides applies the standard environment variables to the base config.
+func applyEnvOverrides(base *BaseConfig) {
+	if port := os.Getenv("PORT"); port != "" {
+		base.HTTPPort = port
+	}
+	if level := os.Getenv("LOG_LEVEL"); level != "" {
+		base.LogLevel = level
+	}
+	if projectID := os.Getenv("PROJECT_ID"); projectID != "" {
+		base.ProjectID = projectID
+	}
+}
+
+// missingRequired walks struct v and returns the YAML paths of zero-valued fields
+// tagged `required:"true"`, descending into nested and inlined structs.
+func missingRequired(v reflect.Value, prefix string) []string {
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	var missing []string
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, inline := yamlFieldName(field)
+		path := prefix + name
+		if inline {
+			path = strings.TrimSuffix(prefix, ".")
+		}
+
+		value := v.Field(i)
+		if field.Tag.Get("required") == "true" && value.IsZero() {
+			missing = append(missing, path)
+			continue
+		}
+		if value.Kind() == reflect.Struct {
+			childPrefix := path + "."
+			if path == "" {
+				childPrefix = ""
+			}
+			missing = append(missing, missingRequired(value, childPrefix)...)
+		}
+	}
+	return missing
+}
+
+// yamlFieldName returns the key yaml.v3 uses for field and whether it is inlined.
+func yamlFieldName(field reflect.StructField) (string, bool) {
+	name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	inline := strings.Contains(opts, "inline")
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, inline
+}
@@ -0,0 +1,101 @@
+package microservice_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type subscriberConfig struct {
+	microservice.BaseConfig `yaml:",inline"`
+	TopicID                 string `yaml:"topic_id" required:"true"`
+	Subscription            struct {
+		Name        string `yaml:"name" required:"true"`
+		MaxInFlight int    `yaml:"max_in_flight"`
+	} `yaml:"subscription"`
+}
+
+type validatedConfig struct {
+	microservice.BaseConfig `yaml:",inline"`
+	Workers                 int `yaml:"workers"`
+}
+
+func (c *validatedConfig) Validate() error {
+	if c.Workers < 1 {
+		return errors.New("workers must be at least 1")
+	}
+	return nil
+}
+
+// privateFieldConfig has an unexported field before the embedded BaseConfig.
+type privateFieldConfig struct {
+	secret                  string
+	microservice.BaseConfig `yaml:",inline"`
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := writeConfig(t, `
+http_port: "8080"
+log_level: debug
+project_id: file-project
+topic_id: orders
+subscription:
+  name: orders-sub
+  max_in_flight: 10
+`)
+	t.Setenv("PROJECT_ID", "env-project")
+
+	cfg, err := microservice.LoadConfig[subscriberConfig](path)
+	require.NoError(t, err)
+	assert.Equal(t, "8080", cfg.HTTPPort)
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, "env-project", cfg.ProjectID, "environment overrides the file")
+	assert.Equal(t, "orders", cfg.TopicID)
+	assert.Equal(t, 10, cfg.Subscription.MaxInFlight)
+}
+
+func TestLoadConfig_UnexportedFields(t *testing.T) {
+	t.Setenv("PORT", "9090")
+
+	cfg, err := microservice.LoadConfig[privateFieldConfig]("")
+	require.NoError(t, err)
+	assert.Equal(t, "9090", cfg.HTTPPort, "the embedded BaseConfig is still found")
+	assert.Empty(t, cfg.secret)
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	t.Run("missing required fields are listed", func(t *testing.T) {
+		_, err := microservice.LoadConfig[subscriberConfig](writeConfig(t, `http_port: "8080"`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "topic_id, subscription.name")
+	})
+
+	t.Run("Validate is called", func(t *testing.T) {
+		_, err := microservice.LoadConfig[validatedConfig](writeConfig(t, `workers: 0`))
+		assert.ErrorContains(t, err, "workers must be at least 1")
+
+		cfg, err := microservice.LoadConfig[validatedConfig](writeConfig(t, `workers: 2`))
+		require.NoError(t, err)
+		assert.Equal(t, 2, cfg.Workers)
+	})
+
+	t.Run("unreadable and malformed files", func(t *testing.T) {
+		_, err := microservice.LoadConfig[subscriberConfig](filepath.Join(t.TempDir(), "missing.yaml"))
+		assert.ErrorContains(t, err, "failed to read config file")
+
+		_, err = microservice.LoadConfig[subscriberConfig](writeConfig(t, "topic_id: [unclosed"))
+		assert.ErrorContains(t, err, "failed to parse config file")
+	})
+}
@@ -0,0 +1,52 @@
+package microservice
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+)
+
+// ConnectionLimits protects small containers from clients that open too many
+// connections or hold them open indefinitely. Zero values mean "no limit".
+type ConnectionLimits struct {
+	// MaxConnections caps the number of concurrently accepted connections.
+	// Further connections wait in the kernel backlog until a slot frees up.
+	MaxConnections int `yaml:"max_connections"`
+	// DisableKeepAlives closes every connection after a single request.
+	DisableKeepAlives bool `yaml:"disable_keep_alives"`
+	// MaxRequestsPerConnection closes a keep-alive connection after it has served this many requests.
+	MaxRequestsPerConnection int `yaml:"max_requests_per_connection"`
+}
+
+// connRequestsKey is the context key for the per-connection request counter.
+type connRequestsKey struct{}
+
+// WithConnectionLimits applies the given connection and keep-alive limits to the server.
+func WithConnectionLimits(limits ConnectionLimits) Option {
+	return func(s *BaseServer) {
+		s.maxConnections = limits.MaxConnections
+		if limits.DisableKeepAlives {
+			s.httpServer.SetKeepAlivesEnabled(false)
+		}
+		if limits.MaxRequestsPerConnection > 0 {
+			s.httpServer.ConnContext = func(ctx context.Context, _ net.Conn) context.Context {
+				return context.WithValue(ctx, connRequestsKey{}, new(atomic.Int64))
+			}
+			s.httpServer.Handler = limitRequestsPerConnection(s.httpServer.Handler, int64(limits.MaxRequestsPerConnection))
+		}
+	}
+}
+
+// limitRequestsPerConnection asks the server to close the connection once it
+// has served max requests, by setting "Connection: close" on the response.
+func limitRequestsPerConnection(next http.Handler, max int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if counter, ok := r.Context().Value(connRequestsKey{}).(*atomic.Int64); ok {
+			if counter.Add(1) >= max {
+				w.Header().Set("Connection", "close")
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,103 @@
+package microservice_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTestServer starts a server with the given options and returns its base URL.
+func startTestServer(t *testing.T, opts ...microservice.Option) (*microservice.BaseServer, string) {
+	t.Helper()
+	server := microservice.NewBaseServer(zerolog.Nop(), ":0", opts...)
+	readyChan := make(chan struct{})
+	server.SetReadyChannel(readyChan)
+	go func() { _ = server.Start() }()
+	<-readyChan
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	})
+	return server, "http://127.0.0.1" + server.GetHTTPPort()
+}
+
+func TestConnectionLimits_MaxRequestsPerConnection(t *testing.T) {
+	_, serverURL := startTestServer(t, microservice.WithConnectionLimits(microservice.ConnectionLimits{
+		MaxRequestsPerConnection: 2,
+	}))
+	client := &http.Client{Transport: &http.Transport{}}
+
+	resp, err := client.Get(serverURL + "/healthz")
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	assert.False(t, resp.Close, "first request should keep the connection alive")
+
+	resp, err = client.Get(serverURL + "/healthz")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.True(t, resp.Close, "second request should close the connection")
+}
+
+func TestConnectionLimits_DisableKeepAlives(t *testing.T) {
+	_, serverURL := startTestServer(t, microservice.WithConnectionLimits(microservice.ConnectionLimits{
+		DisableKeepAlives: true,
+	}))
+
+	resp, err := http.Get(serverURL + "/healthz")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.True(t, resp.Close)
+}
+
+func TestConnectionLimits_MaxConnections(t *testing.T) {
+	release := make(chan struct{})
+	server, serverURL := startTestServer(t, microservice.WithConnectionLimits(microservice.ConnectionLimits{
+		MaxConnections: 1,
+	}))
+	server.Mux().HandleFunc("/block", func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// Occupy the only connection slot.
+	go func() {
+		resp, err := (&http.Client{Transport: &http.Transport{DisableKeepAlives: true}}).Get(serverURL + "/block")
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// A second connection must wait until the first is released.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resp, err := (&http.Client{Transport: &http.Transport{DisableKeepAlives: true}}).Get(serverURL + "/healthz")
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("second connection was served while the limit was reached")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("second connection was never served")
+	}
+}
@@ -0,0 +1,30 @@
+package microservice
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// WithRequestTimeout attaches a default deadline to every request context so that
+// handlers and downstream calls inherit a sane timeout even when none is set explicitly.
+// An earlier deadline already present on the context is kept. A zero timeout is a no-op.
+//
+// Note that long-lived streaming handlers (SSE, WebSockets) will also be bound by this deadline.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(s *BaseServer) {
+		if timeout <= 0 {
+			return
+		}
+		s.httpServer.Handler = withDeadline(s.httpServer.Handler, timeout)
+	}
+}
+
+// withDeadline wraps next so each request context carries the given timeout.
+func withDeadline(next http.Handler, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,30 @@
+package microservice_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRequestTimeout(t *testing.T) {
+	cfg := microservice.BaseConfig{RequestTimeout: 30 * time.Second}
+	server, serverURL := startTestServer(t, cfg.ServerOptions()...)
+
+	var deadline time.Time
+	var hasDeadline bool
+	server.Mux().HandleFunc("/deadline", func(w http.ResponseWriter, r *http.Request) {
+		deadline, hasDeadline = r.Context().Deadline()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	resp, err := http.Get(serverURL + "/deadline")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	require.True(t, hasDeadline, "request context should carry a deadline")
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), deadline, 2*time.Second)
+}
@@ -0,0 +1,204 @@
+package microservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/director"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultHeartbeatInterval = 15 * time.Second
+	// directorRetryDelay caps the backoff between failed registration or configuration attempts.
+	directorRetryDelay = 30 * time.Second
+)
+
+// DirectorConfig configures how the server reports to the service director.
+type DirectorConfig struct {
+	// Version is the version of the service reported on registration.
+	Version string `yaml:"version"`
+	// AdvertiseAddress is where the director should reach this instance, e.g. the
+	// Cloud Run service URL. Defaults to the hostname and HTTP port.
+	AdvertiseAddress string `yaml:"advertise_address"`
+	// HeartbeatInterval is the time between heartbeats. Defaults to 15s.
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval"`
+	// RequireDataflowConfig holds back SetReady(true) until the service's dataflow
+	// configuration has been fetched from the director; see BaseServer.DataflowConfig.
+	RequireDataflowConfig bool `yaml:"require_dataflow_config"`
+}
+
+// WithDirector registers the service with the service director once the server is
+// listening, sends heartbeats carrying the readiness reported by /readyz, and deregisters at the start
+// of Shutdown so the director stops routing to the instance before it drains. The
+// director being unavailable does not stop the service: registration is retried in the
+// background. A nil client is a no-op.
+func WithDirector(client *director.Client, cfg DirectorConfig) Option {
+	return func(s *BaseServer) {
+		if client == nil {
+			return
+		}
+		if cfg.HeartbeatInterval <= 0 {
+			cfg.HeartbeatInterval = defaultHeartbeatInterval
+		}
+		s.director = &directorState{client: client, cfg: cfg, configLoaded: !cfg.RequireDataflowConfig}
+	}
+}
+
+// DataflowConfig returns the dataflow configuration fetched from the director when
+// DirectorConfig.RequireDataflowConfig is set. It reports false until it has been fetched.
+func (s *BaseServer) DataflowConfig() (json.RawMessage, bool) {
+	if s.director == nil {
+		return nil, false
+	}
+	s.director.mu.Lock()
+	defer s.director.mu.Unlock()
+	return s.director.dataflowConfig, s.director.dataflowConfig != nil
+}
+
+// directorState tracks the server's relationship with the service director.
+type directorState struct {
+	client *director.Client
+	cfg    DirectorConfig
+
+	mu             sync.Mutex
+	cancel         context.CancelFunc
+	done           chan struct{}
+	configLoaded   bool
+	wantReady      bool
+	dataflowConfig json.RawMessage
+}
+
+// allowReady records the requested readiness and reports whether it may be applied now.
+func (d *directorState) allowReady(ready bool) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.wantReady = ready
+	return !ready || d.configLoaded
+}
+
+// startDirector runs the registration and heartbeat loop until stopDirector.
+func (s *BaseServer) startDirector() {
+	d := s.director
+	if d == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(s.backgroundCtx)
+	done := make(chan struct{})
+	d.mu.Lock()
+	d.cancel, d.done = cancel, done
+	d.mu.Unlock()
+	go func() {
+		defer close(done)
+		s.runDirector(ctx, d)
+	}()
+}
+
+// runDirector registers the instance, fetches its configuration if required and then
+// sends heartbeats, registering again if the director has forgotten the instance.
+func (s *BaseServer) runDirector(ctx context.Context, d *directorState) {
+	address := d.cfg.AdvertiseAddress
+	if address == "" {
+		host, _ := os.Hostname()
+		address = net.JoinHostPort(host, s.GetHTTPPort()[1:])
+	}
+	log := s.Logger.With().Str("component", "director").Str("instance_id", d.client.InstanceID()).Logger()
+
+	register := func(ctx context.Context) error { return d.client.Register(ctx, address, d.cfg.Version) }
+	if !retryDirector(ctx, log, "register with service director", register) {
+		return
+	}
+	log.Info().Str("address", address).Msg("Registered with service director")
+
+	if d.cfg.RequireDataflowConfig {
+		var cfg json.RawMessage
+		fetch := func(ctx context.Context) (err error) {
+			cfg, err = d.client.DataflowConfig(ctx)
+			return err
+		}
+		if !retryDirector(ctx, log, "fetch dataflow configuration", fetch) {
+			return
+		}
+		d.mu.Lock()
+		d.dataflowConfig, d.configLoaded = cfg, true
+		wantReady := d.wantReady
+		d.mu.Unlock()
+		log.Info().Msg("Fetched dataflow configuration from service director")
+		if wantReady {
+			s.SetReady(true)
+		}
+	}
+
+	ticker := time.NewTicker(d.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ready, _ := s.readiness(ctx)
+		err := d.client.Heartbeat(ctx, ready)
+		if errors.Is(err, director.ErrNotRegistered) {
+			log.Warn().Msg("Service director does not know this instance, registering again")
+			err = register(ctx)
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Msg("Failed to send heartbeat to service director")
+		}
+	}
+}
+
+// retryDirector calls fn with exponential backoff until it succeeds, returning false
+// if ctx is cancelled first.
+func retryDirector(ctx context.Context, log zerolog.Logger, what string, fn func(context.Context) error) bool {
+	delay := time.Second
+	for {
+		err := fn(ctx)
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		log.Warn().Err(err).Dur("retry_in", delay).Msg("Failed to " + what)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return false
+		}
+		delay = min(delay*2, directorRetryDelay)
+	}
+}
+
+// stopDirector stops the heartbeat loop and deregisters the instance.
+func (s *BaseServer) stopDirector(ctx context.Context) {
+	d := s.director
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	cancel, done := d.cancel, d.done
+	d.cancel = nil
+	d.mu.Unlock()
+	if cancel == nil {
+		return // never started, or already stopped
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+	if err := d.client.Deregister(ctx); err != nil {
+		s.Logger.Warn().Err(err).Msg("Failed to deregister from service director")
+		return
+	}
+	s.Logger.Info().Msg("Deregistered from service director")
+}
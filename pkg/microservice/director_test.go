@@ -0,0 +1,132 @@
+package microservice_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/director"
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDirector records the calls made by a single service instance.
+type fakeDirector struct {
+	mu           sync.Mutex
+	registered   int
+	deregistered int
+	heartbeats   []bool
+	configServed chan struct{}
+	releaseCfg   chan struct{}
+}
+
+func newFakeDirector(t *testing.T) (*fakeDirector, string) {
+	d := &fakeDirector{configServed: make(chan struct{}, 1), releaseCfg: make(chan struct{})}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/services/ingest/instances", func(w http.ResponseWriter, r *http.Request) {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.registered++
+	})
+	mux.HandleFunc("PUT /v1/services/ingest/instances/i-1/heartbeat", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]bool
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.heartbeats = append(d.heartbeats, body["ready"])
+	})
+	mux.HandleFunc("DELETE /v1/services/ingest/instances/i-1", func(w http.ResponseWriter, r *http.Request) {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		d.deregistered++
+	})
+	mux.HandleFunc("GET /v1/dataflows/telemetry/services/ingest/config", func(w http.ResponseWriter, r *http.Request) {
+		<-d.releaseCfg
+		_, _ = io.WriteString(w, `{"topic":"readings"}`)
+		d.configServed <- struct{}{}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return d, server.URL
+}
+
+func (d *fakeDirector) snapshot() (registered, deregistered int, heartbeats []bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.registered, d.deregistered, append([]bool(nil), d.heartbeats...)
+}
+
+func TestWithDirector(t *testing.T) {
+	fake, directorURL := newFakeDirector(t)
+	client := director.NewClient(director.Config{
+		URL: directorURL, ServiceName: "ingest", DataflowName: "telemetry", InstanceID: "i-1",
+	})
+	server, serverURL := startTestServer(t, microservice.WithDirector(client, microservice.DirectorConfig{
+		HeartbeatInterval:     20 * time.Millisecond,
+		RequireDataflowConfig: true,
+	}))
+
+	// Without keep-alives no idle probe connection holds up Shutdown below.
+	probe := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	// Readiness is held back until the dataflow configuration has been fetched.
+	server.SetReady(true)
+	resp, err := probe.Get(serverURL + "/readyz")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	_, ok := server.DataflowConfig()
+	assert.False(t, ok)
+
+	close(fake.releaseCfg)
+	<-fake.configServed
+	require.Eventually(t, func() bool {
+		resp, err := probe.Get(serverURL + "/readyz")
+		if err != nil {
+			return false
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 2*time.Second, 10*time.Millisecond)
+	cfg, ok := server.DataflowConfig()
+	require.True(t, ok)
+	assert.JSONEq(t, `{"topic":"readings"}`, string(cfg))
+
+	require.Eventually(t, func() bool {
+		_, _, heartbeats := fake.snapshot()
+		return len(heartbeats) > 0 && heartbeats[len(heartbeats)-1]
+	}, 2*time.Second, 10*time.Millisecond, "heartbeats should report the service as ready")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+	registered, deregistered, _ := fake.snapshot()
+	assert.Equal(t, 1, registered)
+	assert.Equal(t, 1, deregistered)
+}
+
+func TestWithDirector_HeartbeatRunsReadinessChecks(t *testing.T) {
+	fake, directorURL := newFakeDirector(t)
+	client := director.NewClient(director.Config{URL: directorURL, ServiceName: "ingest", InstanceID: "i-1"})
+	server, _ := startTestServer(t, microservice.WithDirector(client, microservice.DirectorConfig{
+		HeartbeatInterval: 20 * time.Millisecond,
+	}))
+	server.RegisterReadinessCheck("pubsub", func(ctx context.Context) error {
+		return errors.New("subscription not found")
+	})
+	server.SetReady(true)
+
+	require.Eventually(t, func() bool {
+		_, _, heartbeats := fake.snapshot()
+		return len(heartbeats) >= 3
+	}, 2*time.Second, 10*time.Millisecond)
+	_, _, heartbeats := fake.snapshot()
+	assert.NotContains(t, heartbeats, true, "a failing readiness check should make heartbeats report not ready")
+}
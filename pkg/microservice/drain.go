@@ -0,0 +1,71 @@
+package microservice
+
+import (
+	"context"
+	"sync"
+)
+
+// ConnectionDrainer is implemented by managers of long-lived connections
+// (WebSocket hubs, SSE brokers) that would otherwise keep Shutdown blocked
+// until its deadline.
+type ConnectionDrainer interface {
+	// Drain is called at the start of shutdown. Implementations should send
+	// close/goaway frames to their clients and return once the connections
+	// have closed or ctx is done.
+	Drain(ctx context.Context) error
+	// ActiveConnections reports how many connections are still open.
+	ActiveConnections() int
+}
+
+// namedDrainer pairs a drainer with the name used in logs.
+type namedDrainer struct {
+	name    string
+	drainer ConnectionDrainer
+}
+
+// RegisterDrainer adds a long-lived connection manager that is notified when
+// Shutdown begins. This is thread-safe.
+func (s *BaseServer) RegisterDrainer(name string, d ConnectionDrainer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.drainers = append(s.drainers, namedDrainer{name: name, drainer: d})
+}
+
+// drainConnections notifies every registered drainer concurrently.
+// The returned channel is closed once all of them have returned.
+func (s *BaseServer) drainConnections(ctx context.Context) <-chan struct{} {
+	s.mu.RLock()
+	drainers := append([]namedDrainer(nil), s.drainers...)
+	s.mu.RUnlock()
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, nd := range drainers {
+		wg.Add(1)
+		go func(nd namedDrainer) {
+			defer wg.Done()
+			s.Logger.Info().Str("drainer", nd.name).Int("connections", nd.drainer.ActiveConnections()).
+				Msg("Draining long-lived connections")
+			if err := nd.drainer.Drain(ctx); err != nil {
+				s.Logger.Warn().Err(err).Str("drainer", nd.name).Msg("Error while draining connections")
+			}
+		}(nd)
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	return done
+}
+
+// logRemainingConnections reports connections that survived draining.
+func (s *BaseServer) logRemainingConnections() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, nd := range s.drainers {
+		if remaining := nd.drainer.ActiveConnections(); remaining > 0 {
+			s.Logger.Warn().Str("drainer", nd.name).Int("remaining_connections", remaining).
+				Msg("Long-lived connections still open after shutdown")
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package microservice_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStreamBroker mimics an SSE broker: each stream stays open until Drain closes it.
+type fakeStreamBroker struct {
+	closing chan struct{}
+	once    sync.Once
+	active  atomic.Int32
+}
+
+func (b *fakeStreamBroker) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	b.active.Add(1)
+	defer b.active.Add(-1)
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+	<-b.closing
+}
+
+func (b *fakeStreamBroker) Drain(ctx context.Context) error {
+	b.once.Do(func() { close(b.closing) })
+	for b.active.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return nil
+}
+
+func (b *fakeStreamBroker) ActiveConnections() int {
+	return int(b.active.Load())
+}
+
+func TestBaseServer_ShutdownDrainsLongLivedConnections(t *testing.T) {
+	server := microservice.NewBaseServer(zerolog.Nop(), ":0")
+	broker := &fakeStreamBroker{closing: make(chan struct{})}
+	server.Mux().Handle("/events", broker)
+	server.RegisterDrainer("events", broker)
+
+	readyChan := make(chan struct{})
+	server.SetReadyChannel(readyChan)
+	go func() { _ = server.Start() }()
+	<-readyChan
+
+	// Open a stream that would otherwise block Shutdown until its deadline.
+	resp, err := http.Get("http://127.0.0.1" + server.GetHTTPPort() + "/events")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Eventually(t, func() bool { return broker.ActiveConnections() == 1 }, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	require.NoError(t, server.Shutdown(ctx))
+
+	assert.Less(t, time.Since(start), 2*time.Second, "Shutdown should not wait for the deadline")
+	assert.Equal(t, 0, broker.ActiveConnections())
+}
@@ -0,0 +1,152 @@
+package microservice
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// GRPCConfig configures the optional gRPC server.
+type GRPCConfig struct {
+	// Port is the gRPC listen port, e.g. "9090". Empty disables gRPC.
+	Port string `yaml:"port"`
+	// EnableReflection registers the server reflection service for tools such as grpcurl.
+	EnableReflection bool `yaml:"enable_reflection"`
+}
+
+// GRPCService is implemented by services that serve gRPC alongside HTTP, so
+// orchestration code such as Run can treat both kinds of service uniformly. A
+// service embedding BaseServer and defining Start(ctx) satisfies it.
+type GRPCService interface {
+	Service
+	GRPCServer() *grpc.Server
+	GetGRPCPort() string
+}
+
+// BaseServer provides the gRPC half of GRPCService to the services embedding it.
+var _ interface {
+	GRPCServer() *grpc.Server
+	GetGRPCPort() string
+} = (*BaseServer)(nil)
+
+// WithGRPC adds a gRPC server that is started and gracefully stopped together with
+// the HTTP server. The standard grpc.health.v1 service is registered and reports the
+// same readiness as /readyz, while /healthz, /readyz and /metrics stay on the HTTP port. Register
+// services on GRPCServer() before calling Start. An empty cfg.Port is a no-op.
+func WithGRPC(cfg GRPCConfig, opts ...grpc.ServerOption) Option {
+	return func(s *BaseServer) {
+		if cfg.Port == "" {
+			return
+		}
+		addr := cfg.Port
+		if !strings.HasPrefix(addr, ":") {
+			addr = ":" + addr
+		}
+
+		s.grpcAddr = addr
+		s.grpcServer = grpc.NewServer(opts...)
+		s.grpcHealth = health.NewServer()
+		s.grpcHealth.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		healthpb.RegisterHealthServer(s.grpcServer, &grpcHealthServer{Server: s.grpcHealth, base: s})
+		if cfg.EnableReflection {
+			reflection.Register(s.grpcServer)
+		}
+	}
+}
+
+// GRPCServer returns the gRPC server for registering services, or nil if WithGRPC
+// was not used.
+func (s *BaseServer) GRPCServer() *grpc.Server {
+	return s.grpcServer
+}
+
+// GetGRPCPort returns the actual port the gRPC server is listening on.
+func (s *BaseServer) GetGRPCPort() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, port, err := net.SplitHostPort(s.actualGRPCAddr)
+	if err != nil {
+		return s.grpcAddr
+	}
+	return ":" + port
+}
+
+// startGRPC listens on the gRPC port and serves in the background.
+func (s *BaseServer) startGRPC() error {
+	if s.grpcServer == nil {
+		return nil
+	}
+	listener, err := net.Listen("tcp", s.grpcAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on gRPC port %s: %w", s.grpcAddr, err)
+	}
+
+	s.mu.Lock()
+	s.actualGRPCAddr = listener.Addr().String()
+	s.mu.Unlock()
+
+	s.Logger.Info().Str("address", s.actualGRPCAddr).Msg("gRPC server starting to listen")
+	go func() {
+		if err := s.grpcServer.Serve(listener); err != nil {
+			s.Logger.Error().Err(err).Msg("gRPC server failed")
+		}
+	}()
+	return nil
+}
+
+// grpcHealthServer evaluates readiness checks on every overall Check, so gRPC health
+// agrees with /readyz rather than only following SetReady.
+type grpcHealthServer struct {
+	*health.Server
+	base *BaseServer
+}
+
+// Check refreshes the overall serving status before answering, which also keeps
+// Watch streams up to date while clients poll.
+func (h *grpcHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if req.GetService() == "" {
+		ready, _ := h.base.readiness(ctx)
+		h.base.setGRPCServing(ready)
+	}
+	return h.Server.Check(ctx, req)
+}
+
+// setGRPCServing mirrors the readiness flag on the gRPC health service. A background
+// component that tripped FailNotReady keeps it NOT_SERVING, as it does /readyz.
+func (s *BaseServer) setGRPCServing(ready bool) {
+	if s.grpcHealth == nil {
+		return
+	}
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if ready && !s.backgroundFailed.Load() {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	s.grpcHealth.SetServingStatus("", status)
+}
+
+// stopGRPC drains in-flight RPCs, forcing the server closed if ctx expires first.
+func (s *BaseServer) stopGRPC(ctx context.Context) {
+	if s.grpcServer == nil {
+		return
+	}
+	s.grpcHealth.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		s.Logger.Info().Msg("gRPC server stopped.")
+	case <-ctx.Done():
+		s.Logger.Warn().Msg("gRPC graceful stop timed out, forcing close.")
+		s.grpcServer.Stop()
+	}
+}
@@ -0,0 +1,101 @@
+package microservice_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+)
+
+// grpcService is shaped like a generated service: it embeds BaseServer and defines Start(ctx).
+type grpcService struct {
+	*microservice.BaseServer
+}
+
+func (s grpcService) Start(_ context.Context) error {
+	return s.BaseServer.Start()
+}
+
+// A gRPC service must also be a Service, so Run accepts it.
+var _ microservice.GRPCService = grpcService{}
+
+func TestBaseServer_GRPC(t *testing.T) {
+	server, _ := startTestServer(t, microservice.WithGRPC(microservice.GRPCConfig{
+		Port:             "0",
+		EnableReflection: true,
+	}))
+	require.NotNil(t, server.GRPCServer())
+
+	conn, err := grpc.NewClient("127.0.0.1"+server.GetGRPCPort(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	healthClient := healthpb.NewHealthClient(conn)
+	resp, err := healthClient.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+
+	server.SetReady(true)
+	resp, err = healthClient.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	reply, err := stream.Recv()
+	require.NoError(t, err)
+	var services []string
+	for _, svc := range reply.GetListServicesResponse().GetService() {
+		services = append(services, svc.GetName())
+	}
+	assert.Contains(t, services, "grpc.health.v1.Health")
+}
+
+func TestBaseServer_GRPCDisabledByDefault(t *testing.T) {
+	server, _ := startTestServer(t, microservice.WithGRPC(microservice.GRPCConfig{}))
+	assert.Nil(t, server.GRPCServer())
+}
+
+func TestBaseServer_GRPCHealthFollowsReadinessChecks(t *testing.T) {
+	server, _ := startTestServer(t, microservice.WithGRPC(microservice.GRPCConfig{Port: "0"}))
+	var healthy atomic.Bool
+	server.RegisterReadinessCheck("database", func(context.Context) error {
+		if !healthy.Load() {
+			return errors.New("database unreachable")
+		}
+		return nil
+	})
+	server.SetReady(true)
+
+	conn, err := grpc.NewClient("127.0.0.1"+server.GetGRPCPort(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	healthClient := healthpb.NewHealthClient(conn)
+	resp, err := healthClient.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status, "a failing readiness check should fail gRPC health too")
+
+	healthy.Store(true)
+	resp, err = healthClient.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+}
@@ -0,0 +1,13 @@
+package microservice
+
+import (
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+)
+
+// WithHTTPMetrics instruments every request with middleware.NewMetricsMiddleware,
+// feeding the server's /metrics endpoint.
+func WithHTTPMetrics(opts ...middleware.MetricsOption) Option {
+	return func(s *BaseServer) {
+		s.httpServer.Handler = middleware.NewMetricsMiddleware(opts...)(s.httpServer.Handler)
+	}
+}
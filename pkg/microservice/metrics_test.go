@@ -0,0 +1,35 @@
+package microservice_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHTTPMetrics_RouteSurvivesOtherOptions(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	server, serverURL := startTestServer(t,
+		microservice.WithRequestTimeout(time.Second),
+		microservice.WithHTTPMetrics(middleware.WithMetricsRegisterer(reg)),
+	)
+	server.Mux().HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	resp, err := http.Get(serverURL + "/items/42")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	expected := `
+# HELP http_server_requests_total HTTP requests handled.
+# TYPE http_server_requests_total counter
+http_server_requests_total{method="GET",route="GET /items/{id}",status_class="2xx"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "http_server_requests_total"))
+}
@@ -0,0 +1,28 @@
+package microservice
+
+import (
+	"github.com/illmade-knight/go-microservice-base/pkg/director"
+)
+
+// Option configures optional BaseServer behaviour. Options are applied by
+// NewBaseServer after the default handlers have been registered.
+type Option func(*BaseServer)
+
+// ServerOptions derives the BaseServer options implied by the configuration,
+// e.g. NewBaseServer(logger, cfg.HTTPPort, cfg.ServerOptions()...).
+func (c BaseConfig) ServerOptions() []Option {
+	opts := []Option{
+		WithConnectionLimits(c.ConnectionLimits),
+		WithRequestTimeout(c.RequestTimeout),
+		WithTracing(c.ServiceName, c.Tracing),
+		WithGRPC(c.GRPC),
+	}
+	if c.ServiceDirectorURL != "" {
+		opts = append(opts, WithDirector(director.NewClient(director.Config{
+			URL:          c.ServiceDirectorURL,
+			ServiceName:  c.ServiceName,
+			DataflowName: c.DataflowName,
+		}), c.Director))
+	}
+	return append(opts, c.TLS.options()...)
+}
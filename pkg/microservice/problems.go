@@ -0,0 +1,14 @@
+package microservice
+
+import (
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+)
+
+// WithProblemDetails installs middleware.UseProblemDetails on the server, so the errors
+// written by the library's middlewares, including panic recovery, are RFC 7807
+// application/problem+json for this server only.
+func WithProblemDetails() Option {
+	return func(s *BaseServer) {
+		s.httpServer.Handler = middleware.UseProblemDetails(s.httpServer.Handler)
+	}
+}
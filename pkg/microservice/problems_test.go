@@ -0,0 +1,52 @@
+package microservice_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/illmade-knight/go-microservice-base/pkg/response"
+	"github.com/illmade-knight/go-microservice-base/pkg/signedurl"
+	"github.com/illmade-knight/go-microservice-base/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithProblemDetails(t *testing.T) {
+	panicking := func(w http.ResponseWriter, r *http.Request) { panic("boom") }
+
+	// Two servers in one process choose their error format independently.
+	problemServer, problemURL := startTestServer(t, microservice.WithProblemDetails())
+	problemServer.Mux().HandleFunc("/panic", panicking)
+	legacyServer, legacyURL := startTestServer(t)
+	legacyServer.Mux().HandleFunc("/panic", panicking)
+
+	resp, err := http.Get(problemURL + "/panic")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, response.ProblemContentType, resp.Header.Get("Content-Type"))
+
+	resp, err = http.Get(legacyURL + "/panic")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+}
+
+func TestWithProblemDetails_OutsideMiddlewarePackage(t *testing.T) {
+	server, serverURL := startTestServer(t, microservice.WithProblemDetails())
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	signer, err := signedurl.NewSigner(signedurl.Key{ID: "k1", Secret: []byte("0123456789abcdef0123456789abcdef")})
+	require.NoError(t, err)
+	server.Mux().Handle("/tenant", tenant.NewMiddleware(tenant.Config{Sources: []tenant.Source{tenant.Header("X-Tenant-ID")}})(ok))
+	server.Mux().Handle("/download", signer.Middleware()(ok))
+
+	for _, path := range []string{"/tenant", "/download"} {
+		resp, err := http.Get(serverURL + path)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.GreaterOrEqual(t, resp.StatusCode, http.StatusBadRequest, path)
+		assert.Equal(t, response.ProblemContentType, resp.Header.Get("Content-Type"), path)
+	}
+}
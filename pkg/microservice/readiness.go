@@ -0,0 +1,138 @@
+package microservice
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/response"
+)
+
+// defaultReadinessTimeout bounds how long /readyz waits for registered checks.
+const defaultReadinessTimeout = 2 * time.Second
+
+// namedCheck pairs a readiness check with the name reported by /readyz.
+type namedCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// CheckResult is the outcome of a single readiness check in the /readyz body.
+type CheckResult struct {
+	Status   string `json:"status"` // "ok" or "failed"
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// ReadinessReport is the JSON body returned by /readyz once checks are registered.
+type ReadinessReport struct {
+	Status string                 `json:"status"` // "ready" or "not_ready"
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// WithReadinessTimeout sets how long /readyz waits for registered checks before
+// reporting them as failed. Defaults to 2 seconds.
+func WithReadinessTimeout(d time.Duration) Option {
+	return func(s *BaseServer) {
+		if d > 0 {
+			s.readinessTimeout = d
+		}
+	}
+}
+
+// RegisterReadinessCheck adds a named dependency check (Pub/Sub, Firestore, a JWKS
+// endpoint, ...) to /readyz. Checks run concurrently on every probe; the service is
+// ready only when SetReady(true) has been called and every check returns nil.
+// Checks must honour ctx cancellation. This is thread-safe.
+func (s *BaseServer) RegisterReadinessCheck(name string, check func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readinessChecks = append(s.readinessChecks, namedCheck{name: name, check: check})
+}
+
+// runReadinessChecks runs all registered checks concurrently within the readiness timeout.
+func (s *BaseServer) runReadinessChecks(ctx context.Context, checks []namedCheck) (map[string]CheckResult, bool) {
+	ctx, cancel := context.WithTimeout(ctx, s.readinessTimeout)
+	defer cancel()
+
+	results := make(map[string]CheckResult, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, nc := range checks {
+		wg.Add(1)
+		go func(nc namedCheck) {
+			defer wg.Done()
+			started := time.Now()
+			err := runCheck(ctx, nc.check)
+			result := CheckResult{Status: "ok", Duration: time.Since(started).Round(time.Millisecond).String()}
+			if err != nil {
+				result.Status = "failed"
+				result.Error = err.Error()
+			}
+			mu.Lock()
+			results[nc.name] = result
+			mu.Unlock()
+		}(nc)
+	}
+	wg.Wait()
+
+	healthy := true
+	for _, result := range results {
+		if result.Status != "ok" {
+			healthy = false
+		}
+	}
+	return results, healthy
+}
+
+// runCheck runs check, giving up when ctx expires even if the check ignores it.
+func runCheck(ctx context.Context, check func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// readiness reports whether the service is ready: SetReady(true) has been called, no
+// background component has failed and every registered check passes. The check results
+// are nil when no checks are registered. Both /readyz and the director heartbeat use it.
+func (s *BaseServer) readiness(ctx context.Context) (bool, map[string]CheckResult) {
+	ready := s.isReady.Load().(bool) && !s.backgroundFailed.Load()
+
+	s.mu.RLock()
+	checks := append([]namedCheck(nil), s.readinessChecks...)
+	s.mu.RUnlock()
+	if len(checks) == 0 {
+		return ready, nil
+	}
+	results, healthy := s.runReadinessChecks(ctx, checks)
+	return ready && healthy, results
+}
+
+// writeReadinessReport writes the JSON report for the given check results.
+func (s *BaseServer) writeReadinessReport(w http.ResponseWriter, ready bool, results map[string]CheckResult) {
+	report := ReadinessReport{Status: "ready", Checks: results}
+	status := http.StatusOK
+	if !ready {
+		report.Status = "not_ready"
+		status = http.StatusServiceUnavailable
+
+		var failed []string
+		for name, result := range results {
+			if result.Status != "ok" {
+				failed = append(failed, name)
+			}
+		}
+		sort.Strings(failed)
+		if len(failed) > 0 {
+			s.Logger.Warn().Strs("failed_checks", failed).Msg("Readiness checks failing")
+		}
+	}
+	response.WriteJSON(w, status, report)
+}
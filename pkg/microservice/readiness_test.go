@@ -0,0 +1,63 @@
+package microservice_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseServer_ReadinessChecks(t *testing.T) {
+	server, serverURL := startTestServer(t, microservice.WithReadinessTimeout(50*time.Millisecond))
+	server.SetReady(true)
+
+	var pubsubErr error
+	server.RegisterReadinessCheck("pubsub", func(ctx context.Context) error { return pubsubErr })
+	server.RegisterReadinessCheck("firestore", func(ctx context.Context) error { return nil })
+
+	probe := func() (int, microservice.ReadinessReport) {
+		resp, err := http.Get(serverURL + "/readyz")
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		var report microservice.ReadinessReport
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+		return resp.StatusCode, report
+	}
+
+	status, report := probe()
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "ready", report.Status)
+	assert.Equal(t, "ok", report.Checks["pubsub"].Status)
+	assert.Equal(t, "ok", report.Checks["firestore"].Status)
+
+	pubsubErr = errors.New("subscription not found")
+	status, report = probe()
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "not_ready", report.Status)
+	assert.Equal(t, "failed", report.Checks["pubsub"].Status)
+	assert.Equal(t, "subscription not found", report.Checks["pubsub"].Error)
+	assert.Equal(t, "ok", report.Checks["firestore"].Status)
+	pubsubErr = nil
+
+	// A hung dependency that ignores ctx fails once the readiness timeout expires.
+	server.RegisterReadinessCheck("jwks", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	status, report = probe()
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "failed", report.Checks["jwks"].Status)
+	assert.Contains(t, report.Checks["jwks"].Error, "deadline exceeded")
+
+	// The manual flag still gates readiness.
+	server.SetReady(false)
+	status, report = probe()
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "not_ready", report.Status)
+}
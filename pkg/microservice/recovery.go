@@ -0,0 +1,30 @@
+package microservice
+
+import (
+	"net/http"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+)
+
+// WithoutPanicRecovery disables the middleware.NewRecoveryMiddleware that BaseServer
+// installs by default, e.g. for a service that installs its own recovery handling.
+func WithoutPanicRecovery() Option {
+	return func(s *BaseServer) {
+		s.disablePanicRecovery = true
+	}
+}
+
+// recoverPanics wraps h with middleware.NewRecoveryMiddleware so a panicking handler
+// gets a 500 response. It is the innermost server middleware, letting options such as
+// WithRequestLogging and WithHTTPMetrics observe that 500. Because the handler chain is
+// built before options are applied, WithoutPanicRecovery is checked per request.
+func (s *BaseServer) recoverPanics(h http.Handler) http.Handler {
+	recovered := middleware.NewRecoveryMiddleware(s.Logger)(h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.disablePanicRecovery {
+			h.ServeHTTP(w, r)
+			return
+		}
+		recovered.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,70 @@
+package microservice_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	panicking := func(w http.ResponseWriter, r *http.Request) { panic("boom") }
+
+	t.Run("installed by default", func(t *testing.T) {
+		server, serverURL := startTestServer(t)
+		server.Mux().HandleFunc("/panic", panicking)
+
+		resp, err := http.Get(serverURL + "/panic")
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	})
+
+	t.Run("opt out", func(t *testing.T) {
+		server, serverURL := startTestServer(t, microservice.WithoutPanicRecovery())
+		server.Mux().HandleFunc("/panic", panicking)
+
+		// net/http drops the connection of a panicking handler.
+		_, err := http.Get(serverURL + "/panic")
+		assert.Error(t, err)
+	})
+}
+
+func TestPanicRecovery_Hijack(t *testing.T) {
+	server, serverURL := startTestServer(t)
+	server.Mux().HandleFunc("/upgrade", func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		if !ok {
+			http.Error(w, "hijacking not supported", http.StatusInternalServerError)
+			return
+		}
+		conn, rw, err := hijacker.Hijack()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\nhello")
+		_ = rw.Flush()
+		// A panic after the hijack must not write a 500 onto the upgraded connection.
+		panic("after hijack")
+	})
+
+	conn, err := net.Dial("tcp", serverURL[len("http://"):])
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	_, err = io.WriteString(conn, "GET /upgrade HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: test\r\n\r\n")
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	rest, _ := io.ReadAll(reader)
+	assert.Equal(t, "hello", string(rest))
+}
@@ -0,0 +1,83 @@
+package microservice
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+)
+
+// RouteGroup registers handlers on the server's ServeMux under a common path prefix,
+// wrapped in the group's middlewares. Routing is still done by the ServeMux, so
+// patterns keep their usual syntax, including methods and wildcards.
+type RouteGroup struct {
+	mux         *http.ServeMux
+	prefix      string
+	middlewares []func(http.Handler) http.Handler
+}
+
+// Group returns a RouteGroup for prefix whose handlers are wrapped in middlewares,
+// the first being the outermost:
+//
+//	api := s.Group("/api/v1", corsMw, authMw)
+//	api.Handle("GET /items/{id}", getItem) // served at GET /api/v1/items/{id}
+//
+// It panics if prefix does not start with "/".
+//
+// Group middlewares only run for requests matching one of the group's patterns. A CORS
+// preflight for "GET /items/{id}" is an OPTIONS request, which the ServeMux answers
+// with 405 before any middleware sees it; register a catch-all so the CORS middleware
+// can answer preflights for the whole group:
+//
+//	api.Handle("OPTIONS /", http.NotFoundHandler())
+func (s *BaseServer) Group(prefix string, middlewares ...func(http.Handler) http.Handler) *RouteGroup {
+	return &RouteGroup{mux: s.mux, prefix: groupPrefix(prefix), middlewares: middlewares}
+}
+
+// Group returns a nested group below g. Its handlers are wrapped in g's middlewares
+// followed by the given ones. It panics if prefix does not start with "/".
+func (g *RouteGroup) Group(prefix string, middlewares ...func(http.Handler) http.Handler) *RouteGroup {
+	return &RouteGroup{
+		mux:         g.mux,
+		prefix:      g.prefix + groupPrefix(prefix),
+		middlewares: append(slices.Clone(g.middlewares), middlewares...),
+	}
+}
+
+// groupPrefix validates a group prefix and strips its trailing slash.
+func groupPrefix(prefix string) string {
+	if !strings.HasPrefix(prefix, "/") {
+		panic(fmt.Sprintf("microservice: route group prefix %q must start with \"/\"", prefix))
+	}
+	return strings.TrimSuffix(prefix, "/")
+}
+
+// Handle registers handler for pattern, e.g. "GET /items", below the group's prefix.
+// Like http.ServeMux.Handle, it panics if the pattern is invalid or conflicts with a
+// registered one. Host-qualified patterns are not supported.
+func (g *RouteGroup) Handle(pattern string, handler http.Handler) {
+	g.mux.Handle(g.pattern(pattern), middleware.Chain(g.middlewares...)(handler))
+}
+
+// HandleFunc registers a handler function for pattern below the group's prefix.
+func (g *RouteGroup) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	g.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// pattern prefixes the path of a ServeMux pattern, keeping its method.
+func (g *RouteGroup) pattern(pattern string) string {
+	method, path, found := strings.Cut(pattern, " ")
+	if !found {
+		method, path = "", pattern
+	}
+	path = strings.TrimLeft(path, " \t")
+	if !strings.HasPrefix(path, "/") {
+		panic(fmt.Sprintf("microservice: route group pattern %q must start with a method or a path", pattern))
+	}
+	if method == "" {
+		return g.prefix + path
+	}
+	return method + " " + g.prefix + path
+}
@@ -0,0 +1,77 @@
+package microservice_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteGroup(t *testing.T) {
+	addHeader := func(value string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Middleware", value)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	server, serverURL := startTestServer(t)
+
+	api := server.Group("/api/v1/", addHeader("api"))
+	api.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "item "+r.PathValue("id"))
+	})
+	admin := api.Group("/admin", addHeader("admin"))
+	admin.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {})
+
+	get := func(path string) *http.Response {
+		t.Helper()
+		resp, err := http.Get(serverURL + path)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = resp.Body.Close() })
+		return resp
+	}
+
+	resp := get("/api/v1/items/42")
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "item 42", string(body))
+	assert.Equal(t, []string{"api"}, resp.Header.Values("X-Middleware"))
+
+	resp = get("/api/v1/admin/stats")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"api", "admin"}, resp.Header.Values("X-Middleware"))
+
+	assert.Equal(t, http.StatusNotFound, get("/items/42").StatusCode)
+}
+
+func TestRouteGroup_InvalidPattern(t *testing.T) {
+	server := microservice.NewBaseServer(zerolog.Nop(), ":0")
+	assert.Panics(t, func() {
+		server.Group("/api").HandleFunc("example.com/items", func(http.ResponseWriter, *http.Request) {})
+	})
+	assert.Panics(t, func() { server.Group("api") })
+	assert.Panics(t, func() { server.Group("/api").Group("v1") })
+}
+
+func TestRouteGroup_CORSPreflight(t *testing.T) {
+	server := microservice.NewBaseServer(zerolog.Nop(), ":0")
+	api := server.Group("/api", middleware.NewCorsMiddleware(middleware.CorsConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+	}))
+	api.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	api.Handle("OPTIONS /", http.NotFoundHandler())
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/items/42", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec := httptest.NewRecorder()
+	server.Mux().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+}
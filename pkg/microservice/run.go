@@ -0,0 +1,143 @@
+package microservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// defaultShutdownTimeout bounds how long a service may take to shut down gracefully.
+const defaultShutdownTimeout = 30 * time.Second
+
+// RunOption configures Run.
+type RunOption func(*runConfig)
+
+type runConfig struct {
+	logger          zerolog.Logger
+	drainPeriod     time.Duration
+	shutdownTimeout time.Duration
+	hooks           []shutdownHook
+}
+
+// shutdownHook is a named cleanup step executed after the service has shut down.
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// WithRunLogger sets the logger used for lifecycle messages. Defaults to a no-op logger.
+func WithRunLogger(logger zerolog.Logger) RunOption {
+	return func(c *runConfig) {
+		c.logger = logger
+	}
+}
+
+// WithDrainPeriod sets how long to keep serving after readiness has been flipped to
+// false, giving load balancers time to stop routing new requests. Defaults to 0.
+func WithDrainPeriod(d time.Duration) RunOption {
+	return func(c *runConfig) {
+		c.drainPeriod = d
+	}
+}
+
+// WithShutdownTimeout bounds Shutdown and the shutdown hooks together, starting after
+// the drain period. Defaults to 30s.
+func WithShutdownTimeout(d time.Duration) RunOption {
+	return func(c *runConfig) {
+		if d > 0 {
+			c.shutdownTimeout = d
+		}
+	}
+}
+
+// WithShutdownHook registers a cleanup step, such as closing a database or flushing a
+// Pub/Sub publisher. Hooks run after Shutdown in LIFO order, so resources opened first
+// are closed last. A failing hook does not prevent the others from running.
+func WithShutdownHook(name string, fn func(ctx context.Context) error) RunOption {
+	return func(c *runConfig) {
+		c.hooks = append(c.hooks, shutdownHook{name: name, fn: fn})
+	}
+}
+
+// readinessSetter is implemented by services embedding *BaseServer.
+type readinessSetter interface {
+	SetReady(ready bool)
+}
+
+// Run starts svc and blocks until ctx is cancelled or SIGINT/SIGTERM arrives. It then
+// marks the service not ready (if it has a SetReady method), waits for the drain period,
+// then shuts the service down and runs the shutdown hooks within the shutdown timeout.
+// Start may either block for the lifetime of the service or return immediately after
+// starting background work.
+//
+//	err := microservice.Run(ctx, svc,
+//		microservice.WithRunLogger(logger),
+//		microservice.WithDrainPeriod(5*time.Second),
+//		microservice.WithShutdownHook("firestore", func(context.Context) error { return fs.Close() }),
+//	)
+func Run(ctx context.Context, svc Service, opts ...RunOption) error {
+	cfg := runConfig{logger: zerolog.Nop(), shutdownTimeout: defaultShutdownTimeout}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	logger := cfg.logger
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- svc.Start(ctx)
+	}()
+
+	var errs []error
+	select {
+	case err := <-errCh:
+		if err != nil {
+			// Shutdown hooks still run: resources opened before Start need releasing.
+			errs = append(errs, fmt.Errorf("service failed to start: %w", err))
+			break
+		}
+		<-ctx.Done()
+	case <-ctx.Done():
+	}
+
+	if len(errs) == 0 {
+		logger.Info().Msg("Shutdown signal received, shutting down...")
+		if rs, ok := svc.(readinessSetter); ok {
+			rs.SetReady(false)
+		}
+		if cfg.drainPeriod > 0 {
+			logger.Info().Dur("drain_period", cfg.drainPeriod).Msg("Waiting for load balancers to drain traffic")
+			time.Sleep(cfg.drainPeriod)
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.shutdownTimeout)
+	defer cancel()
+	if len(errs) == 0 {
+		if err := svc.Shutdown(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("graceful shutdown failed: %w", err))
+		}
+	}
+
+	for i := len(cfg.hooks) - 1; i >= 0; i-- {
+		hook := cfg.hooks[i]
+		if err := hook.fn(shutdownCtx); err != nil {
+			logger.Error().Err(err).Str("hook", hook.name).Msg("Shutdown hook failed")
+			errs = append(errs, fmt.Errorf("shutdown hook %s failed: %w", hook.name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	logger.Info().Msg("Service stopped.")
+	return nil
+}
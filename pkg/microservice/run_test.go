@@ -0,0 +1,99 @@
+package microservice_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lifecycleRecorder is a Service that records the order of lifecycle events.
+type lifecycleRecorder struct {
+	mu       sync.Mutex
+	events   []string
+	started  chan struct{}
+	startErr error
+}
+
+func (l *lifecycleRecorder) record(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *lifecycleRecorder) Events() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.events...)
+}
+
+func (l *lifecycleRecorder) Start(_ context.Context) error {
+	l.record("start")
+	close(l.started)
+	return l.startErr
+}
+
+func (l *lifecycleRecorder) Shutdown(_ context.Context) error {
+	l.record("shutdown")
+	return nil
+}
+
+func (l *lifecycleRecorder) SetReady(ready bool) {
+	if !ready {
+		l.record("not-ready")
+	}
+}
+
+func (l *lifecycleRecorder) Mux() *http.ServeMux { return http.NewServeMux() }
+func (l *lifecycleRecorder) GetHTTPPort() string { return ":0" }
+
+func TestRun_OrderedShutdown(t *testing.T) {
+	svc := &lifecycleRecorder{started: make(chan struct{})}
+	hook := func(name string, err error) microservice.RunOption {
+		return microservice.WithShutdownHook(name, func(context.Context) error {
+			svc.record(name)
+			return err
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- microservice.Run(ctx, svc,
+			microservice.WithDrainPeriod(10*time.Millisecond),
+			microservice.WithShutdownTimeout(time.Second),
+			hook("close-db", nil),
+			hook("flush-pubsub", errors.New("flush failed")),
+		)
+	}()
+
+	<-svc.started
+	cancel()
+
+	select {
+	case err := <-done:
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "shutdown hook flush-pubsub failed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return")
+	}
+	assert.Equal(t, []string{"start", "not-ready", "shutdown", "flush-pubsub", "close-db"}, svc.Events(),
+		"readiness flips before shutdown, and hooks run in LIFO order even after a failure")
+}
+
+func TestRun_StartFailureStillRunsHooks(t *testing.T) {
+	svc := &lifecycleRecorder{started: make(chan struct{}), startErr: errors.New("port in use")}
+
+	err := microservice.Run(context.Background(), svc, microservice.WithShutdownHook("close-db", func(context.Context) error {
+		svc.record("close-db")
+		return nil
+	}))
+	assert.ErrorContains(t, err, "service failed to start: port in use")
+	assert.Equal(t, []string{"start", "close-db"}, svc.Events())
+}
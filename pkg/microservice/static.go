@@ -0,0 +1,155 @@
+package microservice
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StaticConfig configures NewStaticHandler.
+type StaticConfig struct {
+	// SPA serves IndexFile for unknown paths without a file extension, so client-side
+	// routes such as /orders/42 load the app instead of returning 404.
+	SPA bool
+	// IndexFile is served for directories and as the SPA fallback. Defaults to "index.html".
+	IndexFile string
+	// MaxAge is the browser cache lifetime of assets. HTML is always revalidated.
+	// Defaults to 1 hour.
+	MaxAge time.Duration
+	// ImmutablePrefixes lists directories of content-hashed files (e.g. "assets/" for Vite
+	// builds) that are cached for a year and marked immutable.
+	ImmutablePrefixes []string
+	// Precompressed serves "<file>.br" or "<file>.gz" when they exist in the file system
+	// and the client accepts that encoding.
+	Precompressed bool
+}
+
+// HandleStatic serves fsys (typically an embed.FS, narrowed with fs.Sub) under prefix,
+// e.g. s.HandleStatic("/", ui, StaticConfig{SPA: true}). More specific routes such as
+// /healthz and /api/ keep precedence over the static handler.
+func (s *BaseServer) HandleStatic(prefix string, fsys fs.FS, cfg StaticConfig) {
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	s.mux.Handle(prefix, http.StripPrefix(strings.TrimSuffix(prefix, "/"), NewStaticHandler(fsys, cfg)))
+}
+
+// NewStaticHandler returns a handler serving files from fsys with cache headers,
+// optional pre-compressed variants and optional single-page-app fallback.
+func NewStaticHandler(fsys fs.FS, cfg StaticConfig) http.Handler {
+	if cfg.IndexFile == "" {
+		cfg.IndexFile = "index.html"
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = time.Hour
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if name == "" || strings.HasSuffix(r.URL.Path, "/") {
+			name = path.Join(name, cfg.IndexFile)
+		}
+
+		if !isFile(fsys, name) {
+			if info, err := fs.Stat(fsys, name); err == nil && info.IsDir() && isFile(fsys, path.Join(name, cfg.IndexFile)) {
+				name = path.Join(name, cfg.IndexFile)
+			} else if cfg.SPA && path.Ext(name) == "" && isFile(fsys, cfg.IndexFile) {
+				name = cfg.IndexFile
+			} else {
+				http.NotFound(w, r)
+				return
+			}
+		}
+
+		serveStaticFile(w, r, fsys, name, cfg)
+	})
+}
+
+// serveStaticFile writes name, or a pre-compressed variant of it, with cache headers.
+func serveStaticFile(w http.ResponseWriter, r *http.Request, fsys fs.FS, name string, cfg StaticConfig) {
+	header := w.Header()
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		header.Set("Content-Type", ctype)
+	}
+	header.Set("Cache-Control", cacheControl(name, cfg))
+
+	servedName := name
+	if cfg.Precompressed {
+		header.Add("Vary", "Accept-Encoding")
+		accepted := r.Header.Get("Accept-Encoding")
+		for _, enc := range []struct{ token, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+			if acceptsEncoding(accepted, enc.token) && isFile(fsys, name+enc.ext) {
+				header.Set("Content-Encoding", enc.token)
+				servedName = name + enc.ext
+				break
+			}
+		}
+	}
+
+	f, err := fsys.Open(servedName)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+	// embed.FS reports a zero ModTime, which ServeContent treats as unknown.
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// cacheControl picks the Cache-Control policy for a file.
+func cacheControl(name string, cfg StaticConfig) string {
+	if path.Ext(name) == ".html" {
+		return "no-cache"
+	}
+	for _, prefix := range cfg.ImmutablePrefixes {
+		if strings.HasPrefix(name, strings.TrimPrefix(prefix, "/")) {
+			return "public, max-age=31536000, immutable"
+		}
+	}
+	return "public, max-age=" + strconv.Itoa(int(cfg.MaxAge.Seconds()))
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header allows token.
+func acceptsEncoding(header, token string) bool {
+	for _, part := range strings.Split(header, ",") {
+		enc, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(enc), token) {
+			continue
+		}
+		return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+	}
+	return false
+}
+
+// isFile reports whether name exists in fsys and is a regular file.
+func isFile(fsys fs.FS, name string) bool {
+	info, err := fs.Stat(fsys, name)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,97 @@
+package microservice_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticHandler(t *testing.T) {
+	ui := fstest.MapFS{
+		"index.html":              {Data: []byte("<html>app</html>")},
+		"assets/app-3f9a1c.js":    {Data: []byte("console.log('app')")},
+		"assets/app-3f9a1c.js.br": {Data: []byte("brotli-bytes")},
+		"assets/app-3f9a1c.js.gz": {Data: []byte("gzip-bytes")},
+		"favicon.ico":             {Data: []byte("icon")},
+	}
+	handler := microservice.NewStaticHandler(ui, microservice.StaticConfig{
+		SPA:               true,
+		ImmutablePrefixes: []string{"assets/"},
+		Precompressed:     true,
+	})
+
+	get := func(path, acceptEncoding string) *http.Response {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Result()
+	}
+	body := func(resp *http.Response) string {
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(b)
+	}
+
+	t.Run("index is revalidated", func(t *testing.T) {
+		resp := get("/", "")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+		assert.Equal(t, "<html>app</html>", body(resp))
+	})
+
+	t.Run("client-side route falls back to index", func(t *testing.T) {
+		resp := get("/orders/42", "")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "<html>app</html>", body(resp))
+	})
+
+	t.Run("missing asset is not masked by the fallback", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/assets/missing.js", "").StatusCode)
+	})
+
+	t.Run("hashed assets are immutable and pre-compressed", func(t *testing.T) {
+		resp := get("/assets/app-3f9a1c.js", "gzip, deflate, br")
+		assert.Equal(t, "public, max-age=31536000, immutable", resp.Header.Get("Cache-Control"))
+		assert.Equal(t, "br", resp.Header.Get("Content-Encoding"))
+		assert.Contains(t, resp.Header.Get("Content-Type"), "javascript")
+		assert.Equal(t, "brotli-bytes", body(resp))
+
+		resp = get("/assets/app-3f9a1c.js", "gzip")
+		assert.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+		assert.Equal(t, "gzip-bytes", body(resp))
+
+		resp = get("/assets/app-3f9a1c.js", "")
+		assert.Empty(t, resp.Header.Get("Content-Encoding"))
+		assert.Equal(t, "console.log('app')", body(resp))
+	})
+
+	t.Run("other assets use max-age", func(t *testing.T) {
+		assert.Equal(t, "public, max-age=3600", get("/favicon.ico", "").Header.Get("Cache-Control"))
+	})
+}
+
+func TestBaseServer_HandleStatic(t *testing.T) {
+	ui := fstest.MapFS{"index.html": {Data: []byte("ui")}}
+	server, serverURL := startTestServer(t)
+	server.HandleStatic("/ui", ui, microservice.StaticConfig{SPA: true})
+
+	resp, err := http.Get(serverURL + "/ui/settings")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	b, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "ui", string(b))
+
+	resp, err = http.Get(serverURL + "/healthz")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
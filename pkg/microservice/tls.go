@@ -0,0 +1,199 @@
+package microservice
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// certPollInterval is how often certificate files are checked for changes.
+const certPollInterval = 10 * time.Second
+
+// TLSConfig configures TLS from files, for running outside Cloud Run, e.g. behind a mesh.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// ClientCAFile enables mTLS: clients must present a certificate signed by one of these CAs.
+	ClientCAFile string `yaml:"client_ca_file"`
+}
+
+// options returns the BaseServer options implied by the TLS configuration.
+func (c TLSConfig) options() []Option {
+	return []Option{WithTLS(c.CertFile, c.KeyFile), WithClientCA(c.ClientCAFile)}
+}
+
+// WithTLS serves HTTPS on the HTTP port using the given PEM certificate and key. The
+// files are reloaded without a restart when they change on disk or the process receives
+// SIGHUP, so rotated certificates are picked up automatically; a failed reload keeps the
+// previous ones. Empty file names are a no-op. It cannot be combined with WithAutocert.
+func WithTLS(certFile, keyFile string) Option {
+	return func(s *BaseServer) {
+		if certFile == "" && keyFile == "" {
+			return
+		}
+		s.tlsFiles().certFile, s.tlsFiles().keyFile = certFile, keyFile
+	}
+}
+
+// WithClientCA requires clients to present a certificate signed by a CA in caFile (mTLS).
+// It requires WithTLS and is reloaded together with the server certificate. Note that
+// this applies to /healthz and /readyz too, so probes must present a certificate.
+// An empty file name is a no-op.
+func WithClientCA(caFile string) Option {
+	return func(s *BaseServer) {
+		if caFile == "" {
+			return
+		}
+		s.tlsFiles().caFile = caFile
+	}
+}
+
+// tlsFiles returns the server's certificate reloader, creating it on first use.
+func (s *BaseServer) tlsFiles() *certReloader {
+	if s.certReloader == nil {
+		s.certReloader = &certReloader{}
+	}
+	return s.certReloader
+}
+
+// certReloader holds the current certificate and client CA pool loaded from disk.
+type certReloader struct {
+	certFile, keyFile, caFile string
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes map[string]time.Time
+}
+
+// load reads the files from disk, replacing the current certificate and CA pool.
+func (c *certReloader) load() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS key pair: %w", err)
+	}
+
+	var pool *x509.CertPool
+	if c.caFile != "" {
+		pem, err := os.ReadFile(c.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in client CA file %s", c.caFile)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cert = &cert
+	c.clientCA = pool
+	c.modTimes = c.currentModTimes()
+	return nil
+}
+
+// changed reports whether any of the files has been modified since the last load.
+func (c *certReloader) changed() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for name, modTime := range c.currentModTimes() {
+		if !modTime.Equal(c.modTimes[name]) {
+			return true
+		}
+	}
+	return false
+}
+
+// currentModTimes stats the configured files; missing files are reported as the zero time.
+func (c *certReloader) currentModTimes() map[string]time.Time {
+	times := make(map[string]time.Time, 3)
+	for _, name := range []string{c.certFile, c.keyFile, c.caFile} {
+		if name == "" {
+			continue
+		}
+		if info, err := os.Stat(name); err == nil {
+			times[name] = info.ModTime()
+		}
+	}
+	return times
+}
+
+// tlsConfig returns a server TLS config that always uses the latest loaded files.
+func (c *certReloader) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c.mu.RLock()
+			defer c.mu.RUnlock()
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   []string{"h2", "http/1.1"},
+				Certificates: []tls.Certificate{*c.cert},
+			}
+			if c.clientCA != nil {
+				cfg.ClientCAs = c.clientCA
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// prepareTLS loads the certificate files and starts watching them.
+// It returns nil if WithTLS was not used.
+func (s *BaseServer) prepareTLS() (*tls.Config, error) {
+	reloader := s.certReloader
+	if reloader == nil {
+		return nil, nil
+	}
+	if reloader.certFile == "" || reloader.keyFile == "" {
+		return nil, errors.New("TLS requires both a certificate and a key file")
+	}
+	if s.certManager != nil {
+		return nil, errors.New("WithTLS cannot be combined with WithAutocert")
+	}
+	if err := reloader.load(); err != nil {
+		return nil, err
+	}
+
+	// Subscribe before returning so an early SIGHUP does not terminate the process.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go s.watchCertificates(s.backgroundCtx, reloader, hup)
+	return reloader.tlsConfig(), nil
+}
+
+// watchCertificates reloads the certificates on SIGHUP or when the files change,
+// until ctx is cancelled by Shutdown.
+func (s *BaseServer) watchCertificates(ctx context.Context, reloader *certReloader, hup chan os.Signal) {
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(certPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		case <-ticker.C:
+			if !reloader.changed() {
+				continue
+			}
+		}
+		if err := reloader.load(); err != nil {
+			s.Logger.Error().Err(err).Msg("Failed to reload TLS certificates, keeping the previous ones")
+			continue
+		}
+		s.Logger.Info().Msg("TLS certificates reloaded")
+	}
+}
@@ -0,0 +1,74 @@
+package microservice
+
+import (
+	"context"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+// TracingConfig configures OpenTelemetry tracing. Tracing is opt-in.
+type TracingConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Endpoint is the OTLP/HTTP collector address, e.g. "otel-collector:4318".
+	// Empty uses the OTEL_EXPORTER_OTLP_ENDPOINT environment variable or localhost:4318.
+	Endpoint string `yaml:"endpoint"`
+	// Insecure disables TLS to the collector, as is usual for a sidecar.
+	Insecure bool `yaml:"insecure"`
+	// SampleRatio is the fraction of new traces to record, between 0 and 1. Defaults to 1.
+	// Requests continuing a trace follow the caller's sampling decision.
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// WithTracing exports spans to an OTLP collector and installs the tracing middleware.
+// The tracer provider is registered globally, so spans started with otel.Tracer anywhere
+// in the service are exported too. Pending spans are flushed on Shutdown.
+func WithTracing(serviceName string, cfg TracingConfig) Option {
+	return func(s *BaseServer) {
+		if !cfg.Enabled {
+			return
+		}
+
+		var exporterOpts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			exporterOpts = append(exporterOpts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(context.Background(), exporterOpts...)
+		if err != nil {
+			s.Logger.Error().Err(err).Msg("Failed to create OTLP trace exporter, tracing disabled")
+			return
+		}
+
+		ratio := cfg.SampleRatio
+		if ratio <= 0 || ratio > 1 {
+			ratio = 1
+		}
+		provider := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+			sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+		)
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(middleware.Propagator)
+
+		s.tracerProvider = provider
+		s.httpServer.Handler = middleware.NewTracingMiddleware(serviceName)(s.httpServer.Handler)
+	}
+}
+
+// shutdownTracing flushes buffered spans to the collector.
+func (s *BaseServer) shutdownTracing(ctx context.Context) {
+	if s.tracerProvider == nil {
+		return
+	}
+	if err := s.tracerProvider.Shutdown(ctx); err != nil {
+		s.Logger.Error().Err(err).Msg("Failed to flush traces during shutdown")
+	}
+}
@@ -0,0 +1,41 @@
+package microservice_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/microservice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTracing_FlushesSpansOnShutdown(t *testing.T) {
+	var exported atomic.Int32
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			exported.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	server, serverURL := startTestServer(t, microservice.WithTracing("test-service", microservice.TracingConfig{
+		Enabled:  true,
+		Endpoint: strings.TrimPrefix(collector.URL, "http://"),
+		Insecure: true,
+	}))
+
+	resp, err := http.Get(serverURL + "/healthz")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+	assert.Positive(t, exported.Load(), "buffered spans should be exported on Shutdown")
+}
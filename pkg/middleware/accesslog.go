@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// accessLogContextKey holds the mutable accessLogEntry of the current request.
+const accessLogContextKey contextKey = "accessLog"
+
+// RequestIDHeader is the header carrying the request correlation ID.
+const RequestIDHeader = "X-Request-ID"
+
+// accessLogEntry collects fields that are only known inside the handler chain,
+// such as the user ID set by an auth middleware wrapping an individual route.
+type accessLogEntry struct {
+	userID string
+}
+
+// recordUserID makes userID visible to an enclosing request logger.
+func recordUserID(ctx context.Context, userID string) {
+	if entry, ok := ctx.Value(accessLogContextKey).(*accessLogEntry); ok {
+		entry.userID = userID
+	}
+}
+
+// RequestLoggerOption configures NewRequestLogger.
+type RequestLoggerOption func(*requestLoggerConfig)
+
+type requestLoggerConfig struct {
+	sampleRate     float64
+	excludePaths   map[string]bool
+	trustedProxies int
+}
+
+// WithSampling logs only the given fraction (0 < rate <= 1) of successful requests.
+// Requests that end in a 4xx or 5xx status are always logged.
+func WithSampling(rate float64) RequestLoggerOption {
+	return func(c *requestLoggerConfig) {
+		if rate > 0 && rate <= 1 {
+			c.sampleRate = rate
+		}
+	}
+}
+
+// WithExcludedPaths skips logging for requests to the exact given paths, e.g. "/healthz".
+func WithExcludedPaths(paths ...string) RequestLoggerOption {
+	return func(c *requestLoggerConfig) {
+		for _, p := range paths {
+			c.excludePaths[p] = true
+		}
+	}
+}
+
+// WithTrustedProxies logs the client IP appended to X-Forwarded-For by the outermost of
+// n proxies, matching RateLimitConfig.TrustedProxies. Without it the connection's remote
+// address is logged, as entries a client sends itself could be forged.
+func WithTrustedProxies(n int) RequestLoggerOption {
+	return func(c *requestLoggerConfig) {
+		if n > 0 {
+			c.trustedProxies = n
+		}
+	}
+}
+
+// NewRequestLogger writes one structured access-log line per request with the method,
+// path, status, latency, response size, remote IP, user ID and request ID. Successful
+// requests are logged at info level, 4xx at warn and 5xx at error.
+//
+// The user ID is picked up from the JWT auth middlewares even when they are installed
+// on individual routes inside the handler this middleware wraps.
+func NewRequestLogger(logger zerolog.Logger, opts ...RequestLoggerOption) func(http.Handler) http.Handler {
+	cfg := requestLoggerConfig{sampleRate: 1, excludePaths: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.excludePaths[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			entry := &accessLogEntry{}
+			if userID, ok := GetUserIDFromContext(r.Context()); ok {
+				entry.userID = userID
+			}
+			rec := NewResponseRecorder(w)
+			next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), accessLogContextKey, entry)))
+
+			if rec.status < http.StatusBadRequest && cfg.sampleRate < 1 && rand.Float64() >= cfg.sampleRate {
+				return
+			}
+
+			var event *zerolog.Event
+			switch {
+			case rec.status >= http.StatusInternalServerError:
+				event = logger.Error()
+			case rec.status >= http.StatusBadRequest:
+				event = logger.Warn()
+			default:
+				event = logger.Info()
+			}
+
+			// RequestID may run outside or inside this middleware; the response header
+			// covers the latter, including IDs it generated. Without it, fall back to
+			// the ID sent by the client.
+			requestID, _ := GetRequestIDFromContext(r.Context())
+			if requestID == "" {
+				requestID = rec.Header().Get(RequestIDHeader)
+			}
+			if requestID == "" {
+				requestID = r.Header.Get(RequestIDHeader)
+			}
+
+			event.Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Int("status", rec.status).
+				Dur("latency", time.Since(start)).
+				Int64("size", rec.written).
+				Str("remote_ip", trustedClientIP(r, cfg.trustedProxies)).
+				Str("user_id", entry.userID).
+				Str("request_id", requestID).
+				Msg("HTTP request")
+		})
+	}
+}
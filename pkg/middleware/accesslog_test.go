@@ -0,0 +1,91 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-123"}).
+		SignedString([]byte(testLegacySecret))
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle("/private", middleware.NewLegacySharedSecretAuthMiddleware(testLegacySecret)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("hello"))
+		})))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+	handler := middleware.NewRequestLogger(logger, middleware.WithExcludedPaths("/healthz"),
+		middleware.WithTrustedProxies(2))(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/private", nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	req.Header.Set("X-Request-ID", "req-1")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "info", line["level"])
+	assert.Equal(t, "GET", line["method"])
+	assert.Equal(t, "/private", line["path"])
+	assert.Equal(t, float64(200), line["status"])
+	assert.Equal(t, float64(5), line["size"])
+	assert.Equal(t, "203.0.113.7", line["remote_ip"])
+	assert.Equal(t, "user-123", line["user_id"], "user ID set by an inner auth middleware is logged")
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Contains(t, line, "latency")
+
+	buf.Reset()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Empty(t, buf.String(), "excluded paths are not logged")
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/private", nil))
+	assert.Contains(t, buf.String(), `"level":"warn"`)
+}
+
+func TestRequestLogger_IgnoresSpoofedForwardedFor(t *testing.T) {
+	var buf bytes.Buffer
+	handler := middleware.NewRequestLogger(zerolog.New(&buf))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "198.51.100.4:52000"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "198.51.100.4", line["remote_ip"], "without trusted proxies the connection address is logged")
+}
+
+func TestRequestLogger_Sampling(t *testing.T) {
+	var buf bytes.Buffer
+	handler := middleware.NewRequestLogger(zerolog.New(&buf), middleware.WithSampling(0.000001))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/fail" {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}))
+
+	for i := 0; i < 100; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+	}
+	assert.Empty(t, buf.String(), "successful requests are sampled")
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"), "errors are always logged")
+}
@@ -0,0 +1,71 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireRoleAndScope(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(h http.Handler, claims jwt.MapClaims) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if claims != nil {
+			req = req.WithContext(middleware.ContextWithClaims(req.Context(), claims))
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	t.Run("RequireRole", func(t *testing.T) {
+		h := middleware.RequireRole("admin", "operator")(ok)
+		assert.Equal(t, http.StatusOK, serve(h, jwt.MapClaims{"roles": []interface{}{"viewer", "operator"}}))
+		assert.Equal(t, http.StatusOK, serve(h, jwt.MapClaims{"roles": "admin"}))
+		assert.Equal(t, http.StatusForbidden, serve(h, jwt.MapClaims{"roles": []interface{}{"viewer"}}))
+		assert.Equal(t, http.StatusForbidden, serve(h, jwt.MapClaims{}))
+		assert.Equal(t, http.StatusUnauthorized, serve(h, nil))
+	})
+
+	t.Run("RequireScope", func(t *testing.T) {
+		h := middleware.RequireScope("messages:read", "messages:write")(ok)
+		assert.Equal(t, http.StatusOK, serve(h, jwt.MapClaims{"scope": "messages:read messages:write"}))
+		assert.Equal(t, http.StatusOK, serve(h, jwt.MapClaims{"scope": "messages:read", "scp": []interface{}{"messages:write"}}))
+		assert.Equal(t, http.StatusForbidden, serve(h, jwt.MapClaims{"scope": "messages:read"}))
+	})
+}
+
+func TestAuthMiddlewareStoresClaims(t *testing.T) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "user-123",
+		"roles": []string{"admin"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString([]byte(testLegacySecret))
+	require.NoError(t, err)
+
+	var claims jwt.MapClaims
+	handler := middleware.NewLegacySharedSecretAuthMiddleware(testLegacySecret)(
+		middleware.RequireRole("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, _ = middleware.GetClaimsFromContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		})))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "user-123", claims["sub"])
+	assert.Equal(t, []string{"admin"}, middleware.Roles(claims))
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// Chain composes middlewares into one, applied in the order given: the first
+// middleware is the outermost and sees the request first. For example
+//
+//	handler := middleware.Chain(cors, auth, middleware.RequireRole("admin"))(h)
+//
+// is equivalent to cors(auth(middleware.RequireRole("admin")(h))).
+func Chain(middlewares ...func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			h = middlewares[i](h)
+		}
+		return h
+	}
+}
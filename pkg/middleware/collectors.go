@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/illmade-knight/go-microservice-base/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// registerCollector registers c with reg, reusing an identical collector if one is already registered.
+func registerCollector[C prometheus.Collector](reg prometheus.Registerer, c C) C {
+	return metrics.Register(reg, c)
+}
+
+// routeLabel returns the ServeMux pattern that matched the request, which keeps
+// metric cardinality bounded regardless of path parameters. It is only populated
+// after the mux has routed the request, i.e. once next.ServeHTTP has returned, and
+// r must be the request passed to next (see withRouteCapture).
+func routeLabel(r *http.Request) string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	if capture, ok := r.Context().Value(routeContextKey).(*routeCapture); ok && capture.pattern != "" {
+		return capture.pattern
+	}
+	return "unmatched"
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+// DeprecationConfig describes the lifecycle state of a deprecated route group.
+type DeprecationConfig struct {
+	// Name identifies the route group in logs and metrics, e.g. "v1".
+	Name string
+	// DeprecatedAt is when the group was deprecated. If zero, "Deprecation: true" is sent.
+	DeprecatedAt time.Time
+	// Sunset is when the group will be removed. If zero, no Sunset header is sent.
+	Sunset time.Time
+	// DocsURL links to migration documentation (rel="deprecation").
+	DocsURL string
+	// SunsetURL links to the sunset policy (rel="sunset").
+	SunsetURL string
+	// EnforceSunset rejects requests with 410 Gone once the sunset date has passed.
+	EnforceSunset bool
+	// CallerFunc identifies the caller for usage tracking, e.g. by client or service name.
+	// It is used as a metric label, so it must return a bounded set of values. Defaults to
+	// "authenticated" or "anonymous"; the user ID is only recorded in the debug log.
+	CallerFunc func(r *http.Request) string
+	// Logger receives a debug entry for every deprecated call.
+	Logger zerolog.Logger
+	// Registerer is where the usage counter is registered. Defaults to the global registry.
+	Registerer prometheus.Registerer
+}
+
+// NewDeprecationMiddleware marks a route group as deprecated. It emits Deprecation (RFC 9745),
+// Sunset (RFC 8594) and Link headers, records usage by caller so owners can chase
+// remaining consumers, and optionally starts failing requests after the sunset date.
+func NewDeprecationMiddleware(cfg DeprecationConfig) func(http.Handler) http.Handler {
+	callerFunc := cfg.CallerFunc
+	if callerFunc == nil {
+		callerFunc = func(r *http.Request) string {
+			if _, ok := GetUserIDFromContext(r.Context()); ok {
+				return "authenticated"
+			}
+			return "anonymous"
+		}
+	}
+
+	usage := registerCollector(cfg.Registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_deprecated_requests_total",
+		Help: "Requests made to deprecated route groups, by caller.",
+	}, []string{"group", "caller"}))
+
+	deprecation := "true"
+	if !cfg.DeprecatedAt.IsZero() {
+		deprecation = fmt.Sprintf("@%d", cfg.DeprecatedAt.Unix())
+	}
+	var links []string
+	if cfg.DocsURL != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="deprecation"; type="text/html"`, cfg.DocsURL))
+	}
+	if cfg.SunsetURL != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="sunset"; type="text/html"`, cfg.SunsetURL))
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", deprecation)
+			if !cfg.Sunset.IsZero() {
+				w.Header().Set("Sunset", cfg.Sunset.UTC().Format(http.TimeFormat))
+			}
+			for _, link := range links {
+				w.Header().Add("Link", link)
+			}
+
+			caller := callerFunc(r)
+			usage.WithLabelValues(cfg.Name, caller).Inc()
+			userID, _ := GetUserIDFromContext(r.Context())
+			cfg.Logger.Debug().Str("group", cfg.Name).Str("caller", caller).Str("user_id", userID).
+				Str("path", r.URL.Path).Msg("Deprecated endpoint called")
+
+			if cfg.EnforceSunset && !cfg.Sunset.IsZero() && time.Now().After(cfg.Sunset) {
+				WriteError(w, r, http.StatusGone, "Gone: This endpoint has been sunset")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
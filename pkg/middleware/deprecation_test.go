@@ -0,0 +1,66 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecationMiddleware(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	deprecatedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Headers are set before sunset", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		sunset := time.Now().Add(24 * time.Hour)
+		handler := middleware.NewDeprecationMiddleware(middleware.DeprecationConfig{
+			Name:          "v1",
+			DeprecatedAt:  deprecatedAt,
+			Sunset:        sunset,
+			DocsURL:       "https://docs.example.com/migrate-v2",
+			EnforceSunset: true,
+			Registerer:    reg,
+		})(testHandler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(middleware.ContextWithUserID(req.Context(), "user-123"))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "@1735689600", rr.Header().Get("Deprecation"))
+		assert.Equal(t, sunset.UTC().Format(http.TimeFormat), rr.Header().Get("Sunset"))
+		assert.Equal(t, `<https://docs.example.com/migrate-v2>; rel="deprecation"; type="text/html"`, rr.Header().Get("Link"))
+
+		expected := `
+# HELP http_deprecated_requests_total Requests made to deprecated route groups, by caller.
+# TYPE http_deprecated_requests_total counter
+http_deprecated_requests_total{caller="authenticated",group="v1"} 1
+`
+		assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "http_deprecated_requests_total"))
+	})
+
+	t.Run("Requests fail after an enforced sunset", func(t *testing.T) {
+		handler := middleware.NewDeprecationMiddleware(middleware.DeprecationConfig{
+			Name:          "v0",
+			Sunset:        time.Now().Add(-time.Hour),
+			EnforceSunset: true,
+			Registerer:    prometheus.NewRegistry(),
+		})(testHandler)
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Equal(t, http.StatusGone, rr.Code)
+		assert.Equal(t, "true", rr.Header().Get("Deprecation"))
+	})
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/response"
+)
+
+// UseProblemDetails switches the error responses of the library's middlewares, for the
+// requests it wraps, from the legacy {"error": "..."} body to RFC 7807
+// application/problem+json. Install it outside the other middlewares, or use
+// microservice.WithProblemDetails, once clients are ready for the new format; without it
+// the legacy shape is kept so existing clients keep working.
+func UseProblemDetails(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(response.ContextWithProblemDetails(r.Context())))
+	})
+}
+
+// WriteError writes a middleware error response in the format selected by
+// UseProblemDetails; see response.WriteError.
+func WriteError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	response.WriteError(w, r, status, message)
+}
@@ -0,0 +1,37 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/illmade-knight/go-microservice-base/pkg/response"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseProblemDetails(t *testing.T) {
+	legacyHandler := middleware.RequireRole("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	problemHandler := middleware.UseProblemDetails(legacyHandler)
+	doRequest := func(handler http.Handler) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/admin", nil))
+		return rr
+	}
+
+	// Both formats can be served side by side, e.g. by two servers in one process.
+	legacy := doRequest(legacyHandler)
+	assert.Equal(t, "application/json", legacy.Header().Get("Content-Type"))
+	assert.Contains(t, legacy.Body.String(), `"error"`)
+
+	rr := doRequest(problemHandler)
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	assert.Equal(t, response.ProblemContentType, rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Unauthorized",
+		"status": 401,
+		"detail": "Missing authentication",
+		"instance": "/admin"
+	}`, rr.Body.String())
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// FaultInjectionEnvVar must be set to "true" in the environment, in addition to
+// FaultInjectionConfig.Enabled, before any fault is injected. The double guard means
+// a config file copied into production cannot switch chaos testing on by itself.
+const FaultInjectionEnvVar = "FAULT_INJECTION_ENABLED"
+
+// FaultInjectionConfig holds the configuration for the fault injection middleware.
+type FaultInjectionConfig struct {
+	// Enabled opts in to fault injection. It is false by default.
+	Enabled bool
+	// Header marks a request as eligible for faults. Defaults to "X-Fault-Injection".
+	Header string
+	// Percentage of eligible requests (0-100) that receive the configured faults.
+	Percentage float64
+	// Latency is added before the request is handled.
+	Latency time.Duration
+	// ErrorStatus, if non-zero, is returned instead of calling the handler.
+	ErrorStatus int
+	// DropConnection aborts the connection without a response. It takes precedence over ErrorStatus.
+	DropConnection bool
+	// Logger receives a debug entry for every injected fault.
+	Logger zerolog.Logger
+}
+
+// NewFaultInjectionMiddleware injects latency, errors or dropped connections into a
+// percentage of requests carrying the configured header, to support game-day testing
+// of clients. It is a no-op unless both cfg.Enabled and the FAULT_INJECTION_ENABLED
+// environment variable are set.
+func NewFaultInjectionMiddleware(cfg FaultInjectionConfig) func(http.Handler) http.Handler {
+	if !cfg.Enabled || os.Getenv(FaultInjectionEnvVar) != "true" {
+		return func(next http.Handler) http.Handler { return next }
+	}
+
+	header := cfg.Header
+	if header == "" {
+		header = "X-Fault-Injection"
+	}
+	cfg.Logger.Warn().Float64("percentage", cfg.Percentage).Str("header", header).
+		Msg("Fault injection is ENABLED; this must never be used in production.")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(header) == "" || rand.Float64()*100 >= cfg.Percentage {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			log := cfg.Logger.Debug().Str("path", r.URL.Path)
+			if cfg.Latency > 0 {
+				select {
+				case <-time.After(cfg.Latency):
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+			switch {
+			case cfg.DropConnection:
+				log.Msg("Injecting dropped connection")
+				panic(http.ErrAbortHandler)
+			case cfg.ErrorStatus != 0:
+				log.Int("status", cfg.ErrorStatus).Msg("Injecting error response")
+				WriteError(w, r, cfg.ErrorStatus, "Injected fault")
+			default:
+				log.Dur("latency", cfg.Latency).Msg("Injected latency")
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
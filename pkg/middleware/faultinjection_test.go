@@ -0,0 +1,59 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFaultInjectionMiddleware(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	cfg := middleware.FaultInjectionConfig{
+		Enabled:     true,
+		Percentage:  100,
+		Latency:     20 * time.Millisecond,
+		ErrorStatus: http.StatusServiceUnavailable,
+	}
+
+	doRequest := func(handler http.Handler, withHeader bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if withHeader {
+			req.Header.Set("X-Fault-Injection", "on")
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Disabled without the environment guard", func(t *testing.T) {
+		handler := middleware.NewFaultInjectionMiddleware(cfg)(testHandler)
+		assert.Equal(t, http.StatusOK, doRequest(handler, true).Code)
+	})
+
+	t.Run("Injects faults into matching requests", func(t *testing.T) {
+		t.Setenv(middleware.FaultInjectionEnvVar, "true")
+		handler := middleware.NewFaultInjectionMiddleware(cfg)(testHandler)
+
+		start := time.Now()
+		assert.Equal(t, http.StatusServiceUnavailable, doRequest(handler, true).Code)
+		assert.GreaterOrEqual(t, time.Since(start), cfg.Latency)
+
+		// Requests without the header are never affected.
+		assert.Equal(t, http.StatusOK, doRequest(handler, false).Code)
+	})
+
+	t.Run("Drops the connection", func(t *testing.T) {
+		t.Setenv(middleware.FaultInjectionEnvVar, "true")
+		dropCfg := cfg
+		dropCfg.DropConnection = true
+		handler := middleware.NewFaultInjectionMiddleware(dropCfg)(testHandler)
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() { doRequest(handler, true) })
+	})
+}
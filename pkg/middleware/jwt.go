@@ -4,11 +4,11 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/illmade-knight/go-microservice-base/pkg/response"
 	"github.com/lestrrat-go/jwx/v2/jwk"
 )
 
@@ -40,44 +40,111 @@ func NewJWKSManager(jwksURL string) (JWKSManager, error) {
 	return jwk.NewCachedSet(cache, jwksURL), nil
 }
 
+// IssuerConfig describes a trusted token issuer.
+type IssuerConfig struct {
+	// Issuer must equal the token's `iss` claim. An empty Issuer accepts tokens from
+	// any issuer not listed elsewhere; this is how NewJWKSAuthMiddleware behaves.
+	Issuer string
+	// JWKSURL is where the issuer publishes its signing keys.
+	JWKSURL string
+	// Audiences lists accepted `aud` values; the token must contain at least one.
+	// It is required when Issuer is set, unless SkipAudienceCheck is.
+	Audiences []string
+	// SkipAudienceCheck accepts tokens regardless of their `aud` claim. Only use it for
+	// an issuer whose tokens are never minted for another service.
+	SkipAudienceCheck bool
+	// Algorithms lists the accepted signing algorithms. Defaults to RS256.
+	Algorithms []string
+}
+
+// JWKSAuthConfig holds the configuration for NewJWKSAuthMiddlewareWithConfig.
+type JWKSAuthConfig struct {
+	Issuers []IssuerConfig
+}
+
 // NewJWKSAuthMiddleware is the modern, secure constructor for creating JWT authentication middleware.
 // It validates asymmetric RS256 tokens by fetching public keys from a JWKS endpoint.
-// This should be the default choice for all new services.
+// This should be the default choice for all new services. To enforce the token's
+// issuer and audience, or to trust several identity providers, use
+// NewJWKSAuthMiddlewareWithConfig.
 func NewJWKSAuthMiddleware(jwksURL string) (func(http.Handler) http.Handler, error) {
+	return NewJWKSAuthMiddlewareWithConfig(JWKSAuthConfig{
+		Issuers: []IssuerConfig{{JWKSURL: jwksURL}},
+	})
+}
+
+// NewJWKSAuthMiddlewareWithConfig creates JWT authentication middleware trusting several
+// issuers, e.g. the internal identity service and Google. The key set, audiences and
+// algorithms used to validate a token are selected by its `iss` claim, so a token signed
+// by one provider can never be validated against another provider's rules.
+func NewJWKSAuthMiddlewareWithConfig(cfg JWKSAuthConfig) (func(http.Handler) http.Handler, error) {
+	if len(cfg.Issuers) == 0 {
+		return nil, fmt.Errorf("at least one issuer must be configured")
+	}
+
 	// Create a new JWK cache that will automatically fetch and refresh the keys.
 	// This is done once on startup for efficiency.
 	cache := jwk.NewCache(context.Background())
-	err := cache.Register(jwksURL, jwk.WithRefreshInterval(15*time.Minute))
-	if err != nil {
-		return nil, fmt.Errorf("failed to register JWKS URL: %w", err)
-	}
+	issuers := make(map[string]IssuerConfig, len(cfg.Issuers))
+	var validMethods []string
+	for _, issuer := range cfg.Issuers {
+		if _, dup := issuers[issuer.Issuer]; dup {
+			return nil, fmt.Errorf("issuer %q configured more than once", issuer.Issuer)
+		}
+		if issuer.Issuer != "" && len(issuer.Audiences) == 0 && !issuer.SkipAudienceCheck {
+			return nil, fmt.Errorf("issuer %q has no audiences; set Audiences or SkipAudienceCheck", issuer.Issuer)
+		}
+		if len(issuer.Algorithms) == 0 {
+			issuer.Algorithms = []string{"RS256"}
+		}
+		validMethods = append(validMethods, issuer.Algorithms...)
+		issuers[issuer.Issuer] = issuer
 
-	// Pre-fetch the keys on startup to ensure the identity service is reachable.
-	// This makes the service fail-fast if the JWKS endpoint is misconfigured.
-	_, err = cache.Refresh(context.Background(), jwksURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to perform initial JWKS fetch: %w", err)
+		if cache.IsRegistered(issuer.JWKSURL) {
+			continue
+		}
+		if err := cache.Register(issuer.JWKSURL, jwk.WithRefreshInterval(15*time.Minute)); err != nil {
+			return nil, fmt.Errorf("failed to register JWKS URL: %w", err)
+		}
+		// Pre-fetch the keys on startup to ensure the identity service is reachable.
+		// This makes the service fail-fast if the JWKS endpoint is misconfigured.
+		if _, err := cache.Refresh(context.Background(), issuer.JWKSURL); err != nil {
+			return nil, fmt.Errorf("failed to perform initial JWKS fetch: %w", err)
+		}
 	}
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				response.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized: Missing Authorization header")
+				WriteError(w, r, http.StatusUnauthorized, "Unauthorized: Missing Authorization header")
 				return
 			}
 
 			tokenString, found := strings.CutPrefix(authHeader, "Bearer ")
 			if !found {
-				response.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized: Invalid token format")
+				WriteError(w, r, http.StatusUnauthorized, "Unauthorized: Invalid token format")
 				return
 			}
 
-			// The keyfunc is called by the JWT library during parsing.
-			// It fetches the key set from our cache and finds the key that
-			// matches the token's `kid` (Key ID) header.
+			// The keyfunc is called by the JWT library during parsing, after the claims
+			// have been decoded. It selects the issuer by the `iss` claim, checks the
+			// issuer allows the token's algorithm, and finds the key matching the
+			// token's `kid` (Key ID) header in that issuer's key set.
+			var issuer IssuerConfig
 			keyFunc := func(token *jwt.Token) (interface{}, error) {
-				keySet, err := cache.Get(r.Context(), jwksURL)
+				iss, _ := token.Claims.GetIssuer()
+				var ok bool
+				if issuer, ok = issuers[iss]; !ok {
+					if issuer, ok = issuers[""]; !ok {
+						return nil, fmt.Errorf("untrusted issuer '%s'", iss)
+					}
+				}
+				if !slices.Contains(issuer.Algorithms, token.Method.Alg()) {
+					return nil, fmt.Errorf("signing method %s not allowed for issuer '%s'", token.Method.Alg(), iss)
+				}
+
+				keySet, err := cache.Get(r.Context(), issuer.JWKSURL)
 				if err != nil {
 					return nil, fmt.Errorf("failed to get key set from cache: %w", err)
 				}
@@ -100,30 +167,55 @@ func NewJWKSAuthMiddleware(jwksURL string) (func(http.Handler) http.Handler, err
 			}
 
 			// Parse the token, providing our keyfunc to find the correct public key.
-			// We now explicitly require the RS256 signing method.
-			token, err := jwt.Parse(tokenString, keyFunc, jwt.WithValidMethods([]string{"RS256"}))
+			// Per-issuer algorithms are enforced in the keyfunc.
+			token, err := jwt.Parse(tokenString, keyFunc, jwt.WithValidMethods(validMethods))
 
 			if err != nil {
-				response.WriteJSONError(w, http.StatusUnauthorized, fmt.Sprintf("Unauthorized: Invalid token (%s)", err.Error()))
+				WriteError(w, r, http.StatusUnauthorized, fmt.Sprintf("Unauthorized: Invalid token (%s)", err.Error()))
 				return
 			}
 
 			if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+				if !issuer.SkipAudienceCheck && !audienceAllowed(claims, issuer.Audiences) {
+					WriteError(w, r, http.StatusUnauthorized, "Unauthorized: Invalid token audience")
+					return
+				}
+
 				userID, ok := claims["sub"].(string)
 				if !ok || userID == "" {
-					response.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized: Invalid user ID in token")
+					WriteError(w, r, http.StatusUnauthorized, "Unauthorized: Invalid user ID in token")
 					return
 				}
 
 				ctx := context.WithValue(r.Context(), userContextKey, userID)
+				ctx = ContextWithClaims(ctx, claims)
+				recordUserID(ctx, userID)
 				next.ServeHTTP(w, r.WithContext(ctx))
 			} else {
-				response.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized: Invalid token claims")
+				WriteError(w, r, http.StatusUnauthorized, "Unauthorized: Invalid token claims")
 			}
 		})
 	}, nil
 }
 
+// audienceAllowed reports whether the token's `aud` claim contains one of allowed.
+// An empty allowed list accepts any audience.
+func audienceAllowed(claims jwt.MapClaims, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	audiences, err := claims.GetAudience()
+	if err != nil {
+		return false
+	}
+	for _, aud := range audiences {
+		if slices.Contains(allowed, aud) {
+			return true
+		}
+	}
+	return false
+}
+
 // DEPRECATED: NewLegacySharedSecretAuthMiddleware uses a symmetric HS256 shared secret for JWT validation.
 // This pattern is less secure as it requires sharing the secret with all services.
 // It is retained for backward compatibility only and should NOT be used for new services.
@@ -133,13 +225,13 @@ func NewLegacySharedSecretAuthMiddleware(jwtSecret string) func(http.Handler) ht
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				response.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized: Missing Authorization header")
+				WriteError(w, r, http.StatusUnauthorized, "Unauthorized: Missing Authorization header")
 				return
 			}
 
 			tokenString, found := strings.CutPrefix(authHeader, "Bearer ")
 			if !found {
-				response.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized: Invalid token format")
+				WriteError(w, r, http.StatusUnauthorized, "Unauthorized: Invalid token format")
 				return
 			}
 
@@ -151,21 +243,23 @@ func NewLegacySharedSecretAuthMiddleware(jwtSecret string) func(http.Handler) ht
 			})
 
 			if err != nil {
-				response.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized: Invalid token")
+				WriteError(w, r, http.StatusUnauthorized, "Unauthorized: Invalid token")
 				return
 			}
 
 			if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 				userID, ok := claims["sub"].(string)
 				if !ok || userID == "" {
-					response.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized: Invalid user ID in token")
+					WriteError(w, r, http.StatusUnauthorized, "Unauthorized: Invalid user ID in token")
 					return
 				}
 
 				ctx := context.WithValue(r.Context(), userContextKey, userID)
+				ctx = ContextWithClaims(ctx, claims)
+				recordUserID(ctx, userID)
 				next.ServeHTTP(w, r.WithContext(ctx))
 			} else {
-				response.WriteJSONError(w, http.StatusUnauthorized, "Unauthorized: Invalid token claims")
+				WriteError(w, r, http.StatusUnauthorized, "Unauthorized: Invalid token claims")
 			}
 		})
 	}
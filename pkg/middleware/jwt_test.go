@@ -206,3 +206,99 @@ func TestContextHelpers(t *testing.T) {
 	assert.True(t, ok)
 	assert.Equal(t, userID, retrievedID)
 }
+
+// --- Tests for multi-issuer JWKS configuration ---
+
+// createIssuerToken generates an RS256 JWT with the given issuer and audience.
+func createIssuerToken(t *testing.T, issuer, audience, keyID string, privateKey *rsa.PrivateKey) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": "user-123",
+		"iss": issuer,
+		"aud": audience,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = keyID
+	signed, err := token.SignedString(privateKey)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestJWKSAuthMiddlewareWithConfig(t *testing.T) {
+	const internalIssuer, googleIssuer = "https://identity.internal", "https://accounts.google.com"
+
+	internalKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	internalJWKS := newMockJWKSServer(t, "internal-key", &internalKey.PublicKey)
+	defer internalJWKS.Close()
+
+	googleKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	googleJWKS := newMockJWKSServer(t, "google-key", &googleKey.PublicKey)
+	defer googleJWKS.Close()
+
+	jwtMiddleware, err := middleware.NewJWKSAuthMiddlewareWithConfig(middleware.JWKSAuthConfig{
+		Issuers: []middleware.IssuerConfig{
+			{Issuer: internalIssuer, JWKSURL: internalJWKS.URL, Audiences: []string{"key-service"}},
+			{Issuer: googleIssuer, JWKSURL: googleJWKS.URL, Audiences: []string{"https://key-service.run.app"}},
+		},
+	})
+	require.NoError(t, err)
+	protectedHandler := jwtMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	testCases := []struct {
+		name     string
+		token    string
+		expected int
+	}{
+		{"Success - Internal issuer", createIssuerToken(t, internalIssuer, "key-service", "internal-key", internalKey), http.StatusOK},
+		{"Success - Google issuer", createIssuerToken(t, googleIssuer, "https://key-service.run.app", "google-key", googleKey), http.StatusOK},
+		{"Failure - Key from another issuer", createIssuerToken(t, internalIssuer, "key-service", "google-key", googleKey), http.StatusUnauthorized},
+		{"Failure - Untrusted issuer", createIssuerToken(t, "https://evil.example", "key-service", "internal-key", internalKey), http.StatusUnauthorized},
+		{"Failure - Wrong audience", createIssuerToken(t, internalIssuer, "other-service", "internal-key", internalKey), http.StatusUnauthorized},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			rr := httptest.NewRecorder()
+			protectedHandler.ServeHTTP(rr, req)
+			assert.Equal(t, tc.expected, rr.Code)
+		})
+	}
+
+	t.Run("Failure - Algorithm not allowed for issuer", func(t *testing.T) {
+		esOnly, err := middleware.NewJWKSAuthMiddlewareWithConfig(middleware.JWKSAuthConfig{
+			Issuers: []middleware.IssuerConfig{{Issuer: internalIssuer, JWKSURL: internalJWKS.URL, Audiences: []string{"key-service"}, Algorithms: []string{"ES256"}}},
+		})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+createIssuerToken(t, internalIssuer, "key-service", "internal-key", internalKey))
+		rr := httptest.NewRecorder()
+		esOnly(protectedHandler).ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Failure - Issuer without audiences is rejected", func(t *testing.T) {
+		_, err := middleware.NewJWKSAuthMiddlewareWithConfig(middleware.JWKSAuthConfig{
+			Issuers: []middleware.IssuerConfig{{Issuer: internalIssuer, JWKSURL: internalJWKS.URL}},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("Success - Audience check skipped explicitly", func(t *testing.T) {
+		anyAudience, err := middleware.NewJWKSAuthMiddlewareWithConfig(middleware.JWKSAuthConfig{
+			Issuers: []middleware.IssuerConfig{{Issuer: internalIssuer, JWKSURL: internalJWKS.URL, SkipAudienceCheck: true}},
+		})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+createIssuerToken(t, internalIssuer, "other-service", "internal-key", internalKey))
+		rr := httptest.NewRecorder()
+		anyAudience(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})).ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// localesContextKey is the key used to store the negotiated locales.
+const localesContextKey contextKey = "locales"
+
+// NewLocaleMiddleware parses the Accept-Language header, including quality values, and stores
+// the service's supported locales that the client accepts in the request context, best match
+// first. A region-specific request such as "en-GB" also matches a supported "en" (and vice versa).
+// If nothing matches, the first supported locale is used as the default.
+func NewLocaleMiddleware(supported []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Language")
+			locales := matchLocales(parseAcceptLanguage(r.Header.Get("Accept-Language")), supported)
+			ctx := context.WithValue(r.Context(), localesContextKey, locales)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// GetLocales retrieves the negotiated locales, best match first.
+// It returns nil if the locale middleware has not run.
+func GetLocales(ctx context.Context) []string {
+	locales, _ := ctx.Value(localesContextKey).([]string)
+	return locales
+}
+
+// ContextWithLocales is a helper function for tests to inject negotiated locales into a context.
+func ContextWithLocales(ctx context.Context, locales ...string) context.Context {
+	return context.WithValue(ctx, localesContextKey, locales)
+}
+
+// weightedTag is a language range from Accept-Language with its quality value.
+type weightedTag struct {
+	tag string
+	q   float64
+}
+
+// parseAcceptLanguage returns the language ranges ordered by descending quality,
+// preserving header order for equal weights and dropping ranges with q=0.
+func parseAcceptLanguage(header string) []weightedTag {
+	var tags []weightedTag
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if qs, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(qs, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weightedTag{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	return tags
+}
+
+// matchLocales maps the requested ranges onto the supported locales.
+func matchLocales(requested []weightedTag, supported []string) []string {
+	var matched []string
+	seen := make(map[string]bool)
+	add := func(locale string) {
+		if !seen[locale] {
+			seen[locale] = true
+			matched = append(matched, locale)
+		}
+	}
+
+	for _, req := range requested {
+		if req.tag == "*" {
+			for _, s := range supported {
+				add(s)
+			}
+			continue
+		}
+		// Exact matches take priority over matches on the base language.
+		for _, s := range supported {
+			if strings.EqualFold(s, req.tag) {
+				add(s)
+			}
+		}
+		for _, s := range supported {
+			if strings.EqualFold(baseLanguage(s), baseLanguage(req.tag)) {
+				add(s)
+			}
+		}
+	}
+
+	if len(matched) == 0 && len(supported) > 0 {
+		matched = []string{supported[0]}
+	}
+	return matched
+}
+
+// baseLanguage returns the primary subtag, e.g. "en" for "en-GB".
+func baseLanguage(tag string) string {
+	base, _, _ := strings.Cut(tag, "-")
+	return base
+}
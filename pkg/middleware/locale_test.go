@@ -0,0 +1,47 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocaleMiddleware(t *testing.T) {
+	var locales []string
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locales = middleware.GetLocales(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := middleware.NewLocaleMiddleware([]string{"en", "fr-FR", "de"})(testHandler)
+
+	testCases := []struct {
+		name           string
+		acceptLanguage string
+		expected       []string
+	}{
+		{name: "Quality ordering", acceptLanguage: "en;q=0.5, fr-FR", expected: []string{"fr-FR", "en"}},
+		{name: "Base language match", acceptLanguage: "en-GB, fr;q=0.8", expected: []string{"en", "fr-FR"}},
+		{name: "Zero quality is excluded", acceptLanguage: "de;q=0, fr", expected: []string{"fr-FR"}},
+		{name: "Wildcard adds the rest", acceptLanguage: "de, *;q=0.1", expected: []string{"de", "en", "fr-FR"}},
+		{name: "No match falls back to default", acceptLanguage: "ja", expected: []string{"en"}},
+		{name: "Missing header falls back to default", acceptLanguage: "", expected: []string{"en"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tc.acceptLanguage)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expected, locales)
+			assert.Equal(t, "Accept-Language", rr.Header().Get("Vary"))
+		})
+	}
+}
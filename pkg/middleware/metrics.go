@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsOption configures NewMetricsMiddleware.
+type MetricsOption func(*metricsConfig)
+
+type metricsConfig struct {
+	registerer prometheus.Registerer
+	routeFunc  func(*http.Request) string
+}
+
+// WithMetricsRegisterer sets where the metrics are registered. Defaults to the global
+// registry served by BaseServer's /metrics endpoint.
+func WithMetricsRegisterer(reg prometheus.Registerer) MetricsOption {
+	return func(c *metricsConfig) {
+		c.registerer = reg
+	}
+}
+
+// WithRouteLabel supplies the route label for each request, for handlers that are not
+// routed by a ServeMux pattern. It is called after the handler has run; NormalizedPath
+// is a ready-made choice.
+func WithRouteLabel(fn func(*http.Request) string) MetricsOption {
+	return func(c *metricsConfig) {
+		c.routeFunc = fn
+	}
+}
+
+// NewMetricsMiddleware records request count, duration, in-flight requests and response
+// size, labelled by method, route and status class ("2xx", "4xx", ...). The route is the
+// matched ServeMux pattern, e.g. "GET /items/{id}", so path parameters cannot inflate
+// metric cardinality.
+func NewMetricsMiddleware(opts ...MetricsOption) func(http.Handler) http.Handler {
+	cfg := metricsConfig{routeFunc: routeLabel}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	labels := []string{"method", "route", "status_class"}
+	requests := registerCollector(cfg.registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_server_requests_total",
+		Help: "HTTP requests handled.",
+	}, labels))
+	duration := registerCollector(cfg.registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_server_request_duration_seconds",
+		Help:    "Duration of HTTP requests.",
+		Buckets: prometheus.DefBuckets,
+	}, labels))
+	sizes := responseSizeHistogram(cfg.registerer)
+	// The route is only known once the request has been routed, so in-flight
+	// requests are labelled by method alone.
+	inFlight := registerCollector(cfg.registerer, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "http_server_requests_in_flight",
+		Help: "HTTP requests currently being handled.",
+	}, []string{"method"}))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			method := methodLabel(r.Method)
+			inFlight.WithLabelValues(method).Inc()
+			defer inFlight.WithLabelValues(method).Dec()
+
+			start := time.Now()
+			rec := NewResponseRecorder(w)
+			r = withRouteCapture(r)
+			r.Context().Value(routeContextKey).(*routeCapture).sizeRecorded = true
+			next.ServeHTTP(rec, r)
+
+			values := []string{method, cfg.routeFunc(r), statusClass(rec.status)}
+			requests.WithLabelValues(values...).Inc()
+			duration.WithLabelValues(values...).Observe(time.Since(start).Seconds())
+			sizes.WithLabelValues(values...).Observe(float64(rec.written))
+		})
+	}
+}
+
+// responseSizeHistogram is shared by NewMetricsMiddleware and NewResponseLimitMiddleware,
+// so both feed the same series.
+func responseSizeHistogram(reg prometheus.Registerer) *prometheus.HistogramVec {
+	return registerCollector(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_server_response_size_bytes",
+		Help:    "Size of HTTP response bodies in bytes.",
+		Buckets: prometheus.ExponentialBuckets(256, 4, 8),
+	}, []string{"method", "route", "status_class"}))
+}
+
+// statusClass returns the status class label, e.g. "2xx".
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx"
+}
+
+// idSegment matches path segments that are identifiers rather than route structure:
+// numbers, UUIDs and long hex strings.
+var idSegment = regexp.MustCompile(`^([0-9]+|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|[0-9a-fA-F]{16,})$`)
+
+// NormalizedPath is a route label for requests without a ServeMux pattern. It replaces
+// identifier-like path segments with ":id", e.g. "/users/42/orders" becomes "/users/:id/orders".
+func NormalizedPath(r *http.Request) string {
+	if r.Pattern != "" {
+		return r.Pattern
+	}
+	segments := strings.Split(r.URL.Path, "/")
+	for i, segment := range segments {
+		if idSegment.MatchString(segment) {
+			segments[i] = ":id"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// methodLabel bounds the method label to the standard methods.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "OTHER"
+}
@@ -0,0 +1,69 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("item"))
+	})
+
+	// A middleware replacing the request sits between the metrics middleware and the mux,
+	// as WithRequestTimeout does in BaseServer; CaptureRoute keeps the pattern visible.
+	withValue := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), struct{}{}, "v")))
+		})
+	}
+	handler := middleware.NewMetricsMiddleware(middleware.WithMetricsRegisterer(reg))(
+		withValue(middleware.CaptureRoute(mux)))
+
+	for _, path := range []string{"/items/1", "/items/2", "/missing"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	expected := `
+# HELP http_server_requests_total HTTP requests handled.
+# TYPE http_server_requests_total counter
+http_server_requests_total{method="GET",route="GET /items/{id}",status_class="2xx"} 2
+http_server_requests_total{method="GET",route="unmatched",status_class="4xx"} 1
+# HELP http_server_requests_in_flight HTTP requests currently being handled.
+# TYPE http_server_requests_in_flight gauge
+http_server_requests_in_flight{method="GET"} 0
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"http_server_requests_total", "http_server_requests_in_flight"))
+	// One duration and one size series per label combination.
+	assert.Equal(t, 4, testutil.CollectAndCount(reg, "http_server_request_duration_seconds", "http_server_response_size_bytes"))
+}
+
+func TestMetricsMiddleware_NormalizedPath(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	handler := middleware.NewMetricsMiddleware(
+		middleware.WithMetricsRegisterer(reg),
+		middleware.WithRouteLabel(middleware.NormalizedPath),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, path := range []string{"/users/42/orders", "/users/7/orders", "/users/3f2a1c9e-5b7d-4e8f-9a0b-1c2d3e4f5a6b/orders"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	expected := `
+# HELP http_server_requests_total HTTP requests handled.
+# TYPE http_server_requests_total counter
+http_server_requests_total{method="GET",route="/users/:id/orders",status_class="2xx"} 3
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "http_server_requests_total"))
+}
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"container/list"
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit is a token-bucket budget: a sustained rate plus a burst allowance.
+type RateLimit struct {
+	// RequestsPerSecond is the sustained rate at which the budget refills.
+	RequestsPerSecond float64
+	// Burst is the maximum number of requests allowed at once.
+	Burst int
+}
+
+// RateLimitResult is the outcome of taking a token from a RateLimitStore.
+type RateLimitResult struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left in the bucket.
+	Remaining int
+	// RetryAfter is how long until the next token is available when not allowed.
+	RetryAfter time.Duration
+}
+
+// RateLimitStore holds the token buckets of all rate limit keys. The in-memory
+// MemoryRateLimitStore suits a single instance; a Redis implementation (e.g. a Lua
+// script performing the refill-and-take atomically) shares limits across instances.
+type RateLimitStore interface {
+	// Take consumes one token from the bucket for key, creating a full bucket for an
+	// unknown key and applying limit to an existing one. It must be atomic per key.
+	Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// MemoryRateLimitStore is an in-memory RateLimitStore holding a bounded number of
+// buckets. The least recently used bucket is evicted when the store is full; an
+// evicted key simply starts again with a full bucket.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	maxKeys int
+	order   *list.List // front is most recently used; values are *memoryBucket
+	buckets map[string]*list.Element
+}
+
+type memoryBucket struct {
+	key    string
+	bucket *tokenBucket
+}
+
+// NewMemoryRateLimitStore creates a store holding at most maxKeys buckets.
+// A non-positive maxKeys defaults to 10000.
+func NewMemoryRateLimitStore(maxKeys int) *MemoryRateLimitStore {
+	if maxKeys <= 0 {
+		maxKeys = 10000
+	}
+	return &MemoryRateLimitStore{maxKeys: maxKeys, order: list.New(), buckets: make(map[string]*list.Element)}
+}
+
+// Take implements RateLimitStore.
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	var bucket *tokenBucket
+	found := false
+	if elem, ok := s.buckets[key]; ok {
+		s.order.MoveToFront(elem)
+		bucket, found = elem.Value.(*memoryBucket).bucket, true
+	} else {
+		if s.order.Len() >= s.maxKeys {
+			oldest := s.order.Back()
+			s.order.Remove(oldest)
+			delete(s.buckets, oldest.Value.(*memoryBucket).key)
+		}
+		bucket = newTokenBucket(limit.RequestsPerSecond, limit.Burst, now)
+		s.buckets[key] = s.order.PushFront(&memoryBucket{key: key, bucket: bucket})
+	}
+	s.mu.Unlock()
+	if found {
+		// Limits may change at runtime, e.g. after a tenant's plan upgrade.
+		bucket.setLimit(limit.RequestsPerSecond, limit.Burst)
+	}
+
+	allowed, remaining, wait := bucket.take(now)
+	return RateLimitResult{Allowed: allowed, Remaining: remaining, RetryAfter: wait}, nil
+}
+
+// RateLimitConfig holds the configuration for the rate limiting middleware.
+type RateLimitConfig struct {
+	// Limit is the budget applied to each key.
+	Limit RateLimit
+	// Store holds the buckets. Defaults to a MemoryRateLimitStore.
+	Store RateLimitStore
+	// KeyFunc identifies the client. Defaults to the authenticated user ID
+	// ("user:<id>") set by the JWT middlewares, falling back to the client IP
+	// ("ip:<addr>"); see TrustedProxies.
+	KeyFunc func(r *http.Request) string
+	// TrustedProxies is the number of proxies in front of the service that append to
+	// X-Forwarded-For, e.g. 1 behind a single load balancer. The client IP is then the
+	// entry that many hops from the right; entries further left are client-supplied and
+	// ignored. Zero, the default, uses the connection's remote address.
+	TrustedProxies int
+}
+
+// NewRateLimitMiddleware limits each client to cfg.Limit using a token bucket per key.
+// Requests over the limit are rejected with 429 and Retry-After; every response carries
+// X-RateLimit-Limit and X-RateLimit-Remaining headers. Install it after the auth
+// middleware so requests are limited per user rather than per IP.
+//
+// If the store fails (e.g. Redis is unreachable) the request is let through, so an
+// outage of the store does not take the service down with it.
+func NewRateLimitMiddleware(cfg RateLimitConfig) func(http.Handler) http.Handler {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryRateLimitStore(0)
+	}
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = func(r *http.Request) string { return rateLimitKey(r, cfg.TrustedProxies) }
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			result, err := cfg.Store.Take(r.Context(), cfg.KeyFunc(r), cfg.Limit)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			writeRateLimitHeaders(w, cfg.Limit.Burst, result.Remaining, result.RetryAfter)
+			if !result.Allowed {
+				WriteError(w, r, http.StatusTooManyRequests, "Too Many Requests: Rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey is the default RateLimitConfig.KeyFunc.
+func rateLimitKey(r *http.Request, trustedProxies int) string {
+	if userID, ok := GetUserIDFromContext(r.Context()); ok {
+		return "user:" + userID
+	}
+	return "ip:" + trustedClientIP(r, trustedProxies)
+}
+
+// trustedClientIP returns the client IP as seen by the outermost of trustedProxies
+// proxies. It never trusts entries a client could have forged, falling back to the
+// connection's remote address when X-Forwarded-For has too few hops. Rate limiting and
+// access logs both use it, so they agree on who the client is.
+func trustedClientIP(r *http.Request, trustedProxies int) string {
+	if trustedProxies > 0 {
+		var hops []string
+		for _, header := range r.Header.Values("X-Forwarded-For") {
+			for _, hop := range strings.Split(header, ",") {
+				hops = append(hops, strings.TrimSpace(hop))
+			}
+		}
+		if len(hops) >= trustedProxies {
+			return hops[len(hops)-trustedProxies]
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,110 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, middleware.RateLimit) (middleware.RateLimitResult, error) {
+	return middleware.RateLimitResult{}, errors.New("redis unavailable")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	limit := middleware.RateLimit{RequestsPerSecond: 0.001, Burst: 2}
+
+	doRequest := func(handler http.Handler, userID, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		if userID != "" {
+			req = req.WithContext(middleware.ContextWithUserID(req.Context(), userID))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("limits per user with IP fallback", func(t *testing.T) {
+		handler := middleware.NewRateLimitMiddleware(middleware.RateLimitConfig{Limit: limit})(testHandler)
+
+		// Two users behind the same IP have separate budgets.
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, http.StatusOK, doRequest(handler, "alice", "10.0.0.1").Code)
+		}
+		rr := doRequest(handler, "alice", "10.0.0.1")
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+		assert.JSONEq(t, `{"error":"Too Many Requests: Rate limit exceeded"}`, rr.Body.String())
+		assert.Equal(t, http.StatusOK, doRequest(handler, "bob", "10.0.0.1").Code)
+
+		// Anonymous requests are limited by IP.
+		assert.Equal(t, http.StatusOK, doRequest(handler, "", "10.0.0.2").Code)
+		assert.Equal(t, http.StatusOK, doRequest(handler, "", "10.0.0.2").Code)
+		assert.Equal(t, http.StatusTooManyRequests, doRequest(handler, "", "10.0.0.2").Code)
+		assert.Equal(t, http.StatusOK, doRequest(handler, "", "10.0.0.3").Code)
+	})
+
+	t.Run("spoofed X-Forwarded-For does not reset the IP bucket", func(t *testing.T) {
+		handler := middleware.NewRateLimitMiddleware(middleware.RateLimitConfig{Limit: limit})(testHandler)
+		spoofed := func(xff string) int {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "10.0.0.9:1234"
+			req.Header.Set("X-Forwarded-For", xff)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			return rr.Code
+		}
+		assert.Equal(t, http.StatusOK, spoofed("1.1.1.1"))
+		assert.Equal(t, http.StatusOK, spoofed("2.2.2.2"))
+		assert.Equal(t, http.StatusTooManyRequests, spoofed("3.3.3.3"))
+	})
+
+	t.Run("trusted proxies select the hop they appended", func(t *testing.T) {
+		handler := middleware.NewRateLimitMiddleware(middleware.RateLimitConfig{Limit: limit, TrustedProxies: 1})(testHandler)
+		viaProxy := func(xff string) int {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "10.0.0.1:1234" // the load balancer
+			req.Header.Set("X-Forwarded-For", xff)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			return rr.Code
+		}
+		// The client forges the left-most entry; the proxy appends the real address.
+		assert.Equal(t, http.StatusOK, viaProxy("1.1.1.1, 203.0.113.7"))
+		assert.Equal(t, http.StatusOK, viaProxy("2.2.2.2, 203.0.113.7"))
+		assert.Equal(t, http.StatusTooManyRequests, viaProxy("3.3.3.3, 203.0.113.7"))
+		assert.Equal(t, http.StatusOK, viaProxy("203.0.113.8"))
+	})
+
+	t.Run("LRU store evicts the least recently used key", func(t *testing.T) {
+		store := middleware.NewMemoryRateLimitStore(2)
+		handler := middleware.NewRateLimitMiddleware(middleware.RateLimitConfig{
+			Limit: middleware.RateLimit{RequestsPerSecond: 0.001, Burst: 1},
+			Store: store,
+		})(testHandler)
+
+		assert.Equal(t, http.StatusOK, doRequest(handler, "a", "").Code)
+		assert.Equal(t, http.StatusOK, doRequest(handler, "b", "").Code)
+		assert.Equal(t, http.StatusTooManyRequests, doRequest(handler, "a", "").Code) // a is now most recent
+		assert.Equal(t, http.StatusOK, doRequest(handler, "c", "").Code)              // evicts b
+		assert.Equal(t, http.StatusTooManyRequests, doRequest(handler, "a", "").Code)
+		assert.Equal(t, http.StatusOK, doRequest(handler, "b", "").Code, "evicted key starts with a full bucket")
+	})
+
+	t.Run("store failure lets requests through", func(t *testing.T) {
+		handler := middleware.NewRateLimitMiddleware(middleware.RateLimitConfig{
+			Limit: limit, Store: failingRateLimitStore{},
+		})(testHandler)
+		assert.Equal(t, http.StatusOK, doRequest(handler, "", "10.0.0.1").Code)
+	})
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ResponseRecorder captures the status code and body size written by a handler
+// so that observability middleware can report on it after the fact. It forwards
+// Flush, Hijack and Unwrap, so streaming handlers and upgrades keep working.
+type ResponseRecorder struct {
+	http.ResponseWriter
+	status      int
+	written     int64
+	wroteHeader bool
+	// hijacked is set once the handler has taken over the connection.
+	hijacked bool
+}
+
+// NewResponseRecorder wraps w, defaulting the status to 200 as net/http does.
+func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+// Status returns the status code written by the handler, 200 if none was written.
+func (r *ResponseRecorder) Status() int {
+	return r.status
+}
+
+// Written returns the number of body bytes written by the handler.
+func (r *ResponseRecorder) Written() int64 {
+	return r.written
+}
+
+func (r *ResponseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *ResponseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.written += int64(n)
+	return n, err
+}
+
+// Flush supports streaming handlers wrapped by observability middleware.
+func (r *ResponseRecorder) Flush() {
+	r.wroteHeader = true
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Hijack supports WebSocket and other connection upgrades behind observability
+// middleware. After a successful hijack nothing may be written through the recorder.
+func (r *ResponseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking: %w", http.ErrNotSupported)
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err == nil {
+		r.hijacked = true
+		r.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
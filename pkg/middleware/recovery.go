@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+// NewRecoveryMiddleware turns a panicking handler into a 500 JSON error instead of a
+// dropped connection. The panic is logged with its stack trace, route, request ID and
+// user ID, and counted in http_server_panics_total by route. WithMetricsRegisterer and
+// WithRouteLabel apply as for NewMetricsMiddleware.
+//
+// If the handler had already started its response, the status can no longer be changed,
+// so the connection is aborted after logging to make the truncation visible to the client.
+// After the handler hijacked the connection the panic is only logged and counted.
+// Panics with http.ErrAbortHandler are passed through untouched.
+func NewRecoveryMiddleware(logger zerolog.Logger, opts ...MetricsOption) func(http.Handler) http.Handler {
+	cfg := metricsConfig{routeFunc: routeLabel}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	panics := registerCollector(cfg.registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_server_panics_total",
+		Help: "HTTP handler panics recovered.",
+	}, []string{"route"}))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Share the request logger's entry, or start one, so a user ID recorded by
+			// an auth middleware further in is available here.
+			entry, ok := r.Context().Value(accessLogContextKey).(*accessLogEntry)
+			if !ok {
+				entry = &accessLogEntry{}
+				if userID, ok := GetUserIDFromContext(r.Context()); ok {
+					entry.userID = userID
+				}
+				r = r.WithContext(context.WithValue(r.Context(), accessLogContextKey, entry))
+			}
+			r = withRouteCapture(r)
+			rec := NewResponseRecorder(w)
+
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				route := cfg.routeFunc(r)
+				panics.WithLabelValues(route).Inc()
+
+				requestID, _ := GetRequestIDFromContext(r.Context())
+				if requestID == "" {
+					requestID = rec.Header().Get(RequestIDHeader)
+				}
+				logger.Error().
+					Str("panic", fmt.Sprint(recovered)).
+					Str("stack", string(debug.Stack())).
+					Str("method", r.Method).
+					Str("path", r.URL.Path).
+					Str("route", route).
+					Str("user_id", entry.userID).
+					Str("request_id", requestID).
+					Msg("Recovered from panic in HTTP handler")
+
+				switch {
+				case rec.hijacked:
+					// The handler owns the connection; there is no response to write.
+					return
+				case rec.wroteHeader:
+					panic(http.ErrAbortHandler)
+				}
+				WriteError(rec, r, http.StatusInternalServerError, "Internal Server Error: Unexpected error while handling the request")
+			}()
+
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	var logs bytes.Buffer
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /boom/{id}", func(w http.ResponseWriter, r *http.Request) {
+		panic("something broke")
+	})
+	// The auth middleware sits inside, as it would on an individual route.
+	auth := middleware.NewLegacySharedSecretAuthMiddleware("secret")
+	handler := middleware.NewRecoveryMiddleware(zerolog.New(&logs), middleware.WithMetricsRegisterer(reg))(
+		middleware.RequestID(auth(middleware.CaptureRoute(mux))))
+
+	token, err := createTestHS256Token("user-7", "secret")
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodGet, "/boom/1", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-42")
+	req.Header.Set("Authorization", "Bearer "+token)
+	rr := httptest.NewRecorder()
+	require.NotPanics(t, func() { handler.ServeHTTP(rr, req) })
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.JSONEq(t, `{"error":"Internal Server Error: Unexpected error while handling the request"}`, rr.Body.String())
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+	assert.Equal(t, "something broke", entry["panic"])
+	assert.Equal(t, "req-42", entry["request_id"])
+	assert.Equal(t, "user-7", entry["user_id"])
+	assert.Equal(t, "GET /boom/{id}", entry["route"])
+	assert.Contains(t, entry["stack"], "recovery_test.go")
+
+	expected := `
+# HELP http_server_panics_total HTTP handler panics recovered.
+# TYPE http_server_panics_total counter
+http_server_panics_total{route="GET /boom/{id}"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected), "http_server_panics_total"))
+}
+
+func TestRecoveryMiddleware_ResponseAlreadyStarted(t *testing.T) {
+	handler := middleware.NewRecoveryMiddleware(zerolog.Nop(), middleware.WithMetricsRegisterer(prometheus.NewRegistry()))(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("partial"))
+			panic("mid-stream")
+		}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// NonceStore remembers nonces that have already been seen.
+// A Redis or Firestore implementation allows replay protection across instances.
+type NonceStore interface {
+	// CheckAndStore records the nonce until expiresAt. It returns false if the nonce
+	// was already recorded and has not yet expired. It must be atomic.
+	CheckAndStore(ctx context.Context, nonce string, expiresAt time.Time) (bool, error)
+}
+
+// MemoryNonceStore is an in-memory NonceStore suitable for single-instance services and tests.
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryNonceStore creates an empty MemoryNonceStore.
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{nonces: make(map[string]time.Time), lastSweep: time.Now()}
+}
+
+// CheckAndStore implements NonceStore. Expired nonces are swept lazily.
+func (s *MemoryNonceStore) CheckAndStore(_ context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		for n, exp := range s.nonces {
+			if now.After(exp) {
+				delete(s.nonces, n)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	if exp, seen := s.nonces[nonce]; seen && now.Before(exp) {
+		return false, nil
+	}
+	s.nonces[nonce] = expiresAt
+	return true, nil
+}
+
+// ReplayProtectionConfig holds the configuration for the replay protection middleware.
+type ReplayProtectionConfig struct {
+	// Store records seen nonces. Defaults to a MemoryNonceStore.
+	Store NonceStore
+	// MaxSkew is how far the request timestamp may deviate from the server clock. Defaults to 5 minutes.
+	MaxSkew time.Duration
+	// NonceHeader carries a unique value per request. Defaults to "X-Nonce".
+	NonceHeader string
+	// TimestampHeader carries the request time in Unix seconds. Defaults to "X-Timestamp".
+	TimestampHeader string
+}
+
+// NewReplayProtectionMiddleware rejects signed requests that reuse a nonce or carry a stale
+// timestamp. It must run after the HMAC signature check, and the signature must cover both
+// headers; otherwise an attacker can simply replace them.
+//
+// Nonces are remembered for twice MaxSkew, which covers the whole window in which the
+// request's timestamp would still be accepted.
+func NewReplayProtectionMiddleware(cfg ReplayProtectionConfig) func(http.Handler) http.Handler {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryNonceStore()
+	}
+	if cfg.MaxSkew <= 0 {
+		cfg.MaxSkew = 5 * time.Minute
+	}
+	if cfg.NonceHeader == "" {
+		cfg.NonceHeader = "X-Nonce"
+	}
+	if cfg.TimestampHeader == "" {
+		cfg.TimestampHeader = "X-Timestamp"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			nonce := r.Header.Get(cfg.NonceHeader)
+			if nonce == "" {
+				WriteError(w, r, http.StatusUnauthorized, "Unauthorized: Missing nonce")
+				return
+			}
+
+			unix, err := strconv.ParseInt(r.Header.Get(cfg.TimestampHeader), 10, 64)
+			if err != nil {
+				WriteError(w, r, http.StatusUnauthorized, "Unauthorized: Invalid timestamp")
+				return
+			}
+			now := time.Now()
+			timestamp := time.Unix(unix, 0)
+			if timestamp.Before(now.Add(-cfg.MaxSkew)) || timestamp.After(now.Add(cfg.MaxSkew)) {
+				WriteError(w, r, http.StatusUnauthorized, "Unauthorized: Stale timestamp")
+				return
+			}
+
+			fresh, err := cfg.Store.CheckAndStore(r.Context(), nonce, now.Add(2*cfg.MaxSkew))
+			if err != nil {
+				WriteError(w, r, http.StatusInternalServerError, "Internal Server Error: Unable to verify nonce")
+				return
+			}
+			if !fresh {
+				WriteError(w, r, http.StatusUnauthorized, "Unauthorized: Nonce already used")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayProtectionMiddleware(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := middleware.NewReplayProtectionMiddleware(middleware.ReplayProtectionConfig{})(testHandler)
+
+	doRequest := func(nonce string, timestamp time.Time) int {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("X-Nonce", nonce)
+		req.Header.Set("X-Timestamp", strconv.FormatInt(timestamp.Unix(), 10))
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	testCases := []struct {
+		name         string
+		nonce        string
+		timestamp    time.Time
+		expectedCode int
+	}{
+		{name: "Success - Fresh nonce", nonce: "nonce-1", timestamp: time.Now(), expectedCode: http.StatusOK},
+		{name: "Failure - Reused nonce", nonce: "nonce-1", timestamp: time.Now(), expectedCode: http.StatusUnauthorized},
+		{name: "Failure - Stale timestamp", nonce: "nonce-2", timestamp: time.Now().Add(-10 * time.Minute), expectedCode: http.StatusUnauthorized},
+		{name: "Failure - Future timestamp", nonce: "nonce-3", timestamp: time.Now().Add(10 * time.Minute), expectedCode: http.StatusUnauthorized},
+		{name: "Failure - Missing nonce", nonce: "", timestamp: time.Now(), expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedCode, doRequest(tc.nonce, tc.timestamp))
+		})
+	}
+}
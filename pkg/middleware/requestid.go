@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// requestIDContextKey is the key used to store the request correlation ID.
+const requestIDContextKey contextKey = "requestID"
+
+// maxRequestIDLength bounds IDs accepted from clients so they cannot bloat logs.
+const maxRequestIDLength = 128
+
+// RequestID reads the X-Request-ID header, or generates a UUID when it is missing or
+// malformed, stores it in the request context and echoes it on the response.
+// Install it outermost so every other middleware sees the ID.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newUUID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(ContextWithRequestID(r.Context(), id)))
+	})
+}
+
+// GetRequestIDFromContext safely retrieves the request ID from the request context.
+func GetRequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDContextKey).(string)
+	return id, ok
+}
+
+// ContextWithRequestID stores a request ID in ctx, e.g. for a Pub/Sub message
+// that carries the ID of the request that produced it.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, id)
+}
+
+// requestIDTransport forwards the request ID from the outbound request's context.
+type requestIDTransport struct {
+	base http.RoundTripper
+}
+
+// NewRequestIDTransport wraps base (http.DefaultTransport if nil) so outbound requests
+// made with a context carrying a request ID send it as X-Request-ID:
+//
+//	client := &http.Client{Transport: middleware.NewRequestIDTransport(nil)}
+//	req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, url, nil)
+func NewRequestIDTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &requestIDTransport{base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id, ok := GetRequestIDFromContext(req.Context())
+	if !ok || req.Header.Get(RequestIDHeader) != "" {
+		return t.base.RoundTrip(req)
+	}
+	// RoundTrippers must not modify the caller's request.
+	req = req.Clone(req.Context())
+	req.Header.Set(RequestIDHeader, id)
+	return t.base.RoundTrip(req)
+}
+
+// validRequestID accepts non-empty IDs of printable ASCII within the length limit.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,61 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	handler := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = middleware.GetRequestIDFromContext(r.Context())
+	}))
+
+	t.Run("incoming ID is kept", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Request-ID", "abc-123")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		assert.Equal(t, "abc-123", seen)
+		assert.Equal(t, "abc-123", rr.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("missing or malformed ID is generated", func(t *testing.T) {
+		for _, incoming := range []string{"", "bad id\nwith newline"} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("X-Request-ID", incoming)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			assert.Regexp(t, uuidPattern, seen)
+			assert.Equal(t, seen, rr.Header().Get("X-Request-ID"))
+		}
+	})
+}
+
+func TestRequestIDTransport(t *testing.T) {
+	var received string
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("X-Request-ID")
+	}))
+	defer downstream.Close()
+
+	client := &http.Client{Transport: middleware.NewRequestIDTransport(nil)}
+	ctx := middleware.ContextWithRequestID(t.Context(), "req-42")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downstream.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, "req-42", received)
+	assert.Empty(t, req.Header.Get("X-Request-ID"), "the caller's request is not modified")
+}
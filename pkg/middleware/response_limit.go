@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+// errResponseTooLarge is returned from Write once a response has exceeded its limit.
+var errResponseTooLarge = errors.New("response exceeds maximum size")
+
+// ResponseLimitConfig holds the configuration for the response limit middleware.
+type ResponseLimitConfig struct {
+	// MaxBytes is the largest response body allowed. Zero disables enforcement,
+	// leaving only the size metrics.
+	MaxBytes int64
+	// Logger receives a warning for every response that exceeds MaxBytes.
+	Logger zerolog.Logger
+	// Registerer is where the metrics are registered. Defaults to the global
+	// registry served by BaseServer's /metrics endpoint.
+	Registerer prometheus.Registerer
+}
+
+// NewResponseLimitMiddleware records response body sizes per route and, when MaxBytes is
+// set, catches endpoints that accidentally serialize unbounded datasets, counting them in
+// http_response_size_limit_exceeded_total.
+//
+// Sizes go to the http_server_response_size_bytes histogram, unless NewMetricsMiddleware
+// (e.g. via WithHTTPMetrics) also handles the request and records them itself, so
+// installing both never counts a response twice.
+//
+// With a limit, responses are buffered up to MaxBytes so that an oversized response can still be
+// replaced with a 500 JSON error; headers the handler set, such as Content-Length or
+// ETag, are discarded with it. If the handler flushes before reaching the limit, the
+// buffered part is sent and any further oversized write fails instead.
+func NewResponseLimitMiddleware(cfg ResponseLimitConfig) func(http.Handler) http.Handler {
+	exceeded := registerCollector(cfg.Registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_response_size_limit_exceeded_total",
+		Help: "Responses that exceeded the configured maximum size.",
+	}, []string{"method", "route"}))
+
+	sizes := responseSizeHistogram(cfg.Registerer)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := NewResponseRecorder(w)
+			r = withRouteCapture(r)
+			defer func() {
+				if capture := r.Context().Value(routeContextKey).(*routeCapture); !capture.sizeRecorded {
+					sizes.WithLabelValues(methodLabel(r.Method), routeLabel(r), statusClass(rec.status)).
+						Observe(float64(rec.written))
+				}
+			}()
+
+			if cfg.MaxBytes <= 0 {
+				next.ServeHTTP(rec, r)
+				return
+			}
+			sw := &sizeLimitWriter{ResponseWriter: rec, max: cfg.MaxBytes, header: w.Header().Clone()}
+			next.ServeHTTP(sw, r)
+
+			if !sw.exceeded {
+				sw.commit()
+				return
+			}
+			route := routeLabel(r)
+			exceeded.WithLabelValues(r.Method, route).Inc()
+			cfg.Logger.Error().Str("method", r.Method).Str("route", route).Int64("max_bytes", cfg.MaxBytes).
+				Msg("Response exceeded maximum size")
+			if !sw.committed {
+				sw.restoreHeader()
+				WriteError(rec, r, http.StatusInternalServerError, "Internal Server Error: Response too large")
+			}
+		})
+	}
+}
+
+// sizeLimitWriter counts response bytes and holds the response back until it is
+// known to fit (or the handler flushes).
+type sizeLimitWriter struct {
+	http.ResponseWriter
+	max int64
+	// header is the header set before the handler ran, restored when its response is discarded.
+	header    http.Header
+	status    int
+	buf       bytes.Buffer
+	written   int64
+	committed bool
+	exceeded  bool
+}
+
+func (w *sizeLimitWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *sizeLimitWriter) Write(b []byte) (int, error) {
+	if w.exceeded {
+		return 0, errResponseTooLarge
+	}
+	if w.written+int64(len(b)) > w.max {
+		w.exceeded = true
+		w.buf.Reset()
+		return 0, errResponseTooLarge
+	}
+
+	w.written += int64(len(b))
+	if w.committed {
+		return w.ResponseWriter.Write(b)
+	}
+	return w.buf.Write(b)
+}
+
+// Flush sends everything buffered so far; from then on the status can no longer change.
+func (w *sizeLimitWriter) Flush() {
+	if w.exceeded {
+		return
+	}
+	w.commit()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// commit writes the buffered status and body to the underlying writer.
+func (w *sizeLimitWriter) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.buf.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+	}
+}
+
+// restoreHeader drops the headers set by the handler, e.g. a Content-Length or
+// Content-Encoding that would not match the error written in place of its response.
+func (w *sizeLimitWriter) restoreHeader() {
+	h := w.ResponseWriter.Header()
+	for key := range h {
+		if _, ok := w.header[key]; !ok {
+			delete(h, key)
+		}
+	}
+	for key, values := range w.header {
+		h[key] = values
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *sizeLimitWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
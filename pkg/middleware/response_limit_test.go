@@ -0,0 +1,124 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/illmade-knight/go-microservice-base/pkg/response"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseLimitMiddleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	limitMiddleware := middleware.NewResponseLimitMiddleware(middleware.ResponseLimitConfig{
+		MaxBytes:   16,
+		Logger:     zerolog.Nop(),
+		Registerer: reg,
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /small", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("tiny"))
+	})
+	mux.HandleFunc("GET /large", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(strings.Repeat("x", 10)))
+		_, _ = w.Write([]byte(strings.Repeat("x", 10)))
+	})
+	mux.HandleFunc("GET /large-with-headers", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "20")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("ETag", `"abc"`)
+		_, _ = w.Write([]byte(strings.Repeat("x", 20)))
+	})
+	handler := limitMiddleware(mux)
+
+	t.Run("Response within limit is passed through", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/small", nil))
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.Equal(t, "tiny", rr.Body.String())
+	})
+
+	t.Run("Oversized response is replaced with a 500", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/large", nil))
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+
+		var apiErr response.APIError
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &apiErr))
+		assert.Contains(t, apiErr.Error, "Response too large")
+	})
+
+	t.Run("Headers set by the handler are dropped with an oversized response", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		rr.Header().Set("X-Request-ID", "req-1")
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/large-with-headers", nil))
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Empty(t, rr.Header().Get("Content-Length"))
+		assert.Empty(t, rr.Header().Get("Content-Encoding"))
+		assert.Empty(t, rr.Header().Get("ETag"))
+		assert.Equal(t, "req-1", rr.Header().Get("X-Request-ID"), "headers set before the handler should be kept")
+
+		var apiErr response.APIError
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &apiErr))
+	})
+
+	t.Run("Exceeded responses are counted per route", func(t *testing.T) {
+		exceeded, err := testutil.GatherAndCount(reg, "http_response_size_limit_exceeded_total")
+		require.NoError(t, err)
+		assert.Equal(t, 2, exceeded)
+	})
+}
+
+func TestResponseLimitMiddleware_RecordsSizes(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	})
+
+	// sizeSamples returns how many responses were recorded in the size histogram.
+	sizeSamples := func(t *testing.T, reg *prometheus.Registry) uint64 {
+		t.Helper()
+		families, err := reg.Gather()
+		require.NoError(t, err)
+		var count uint64
+		for _, family := range families {
+			if family.GetName() == "http_server_response_size_bytes" {
+				for _, metric := range family.GetMetric() {
+					count += metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+		return count
+	}
+
+	t.Run("Without the metrics middleware", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		handler := middleware.NewResponseLimitMiddleware(middleware.ResponseLimitConfig{Registerer: reg})(mux)
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+		assert.Equal(t, uint64(1), sizeSamples(t, reg))
+	})
+
+	t.Run("Alongside the metrics middleware sizes are recorded once", func(t *testing.T) {
+		reg := prometheus.NewRegistry()
+		limit := middleware.NewResponseLimitMiddleware(middleware.ResponseLimitConfig{MaxBytes: 1024, Registerer: reg})
+		metrics := middleware.NewMetricsMiddleware(middleware.WithMetricsRegisterer(reg))
+
+		// Either order: metrics outside, as WithHTTPMetrics installs it, or inside.
+		for _, handler := range []http.Handler{metrics(limit(mux)), limit(metrics(mux))} {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items", nil))
+		}
+		assert.Equal(t, uint64(2), sizeSamples(t, reg))
+	})
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+// routeContextKey holds the *routeCapture of the current request.
+const routeContextKey contextKey = "route"
+
+// routeCapture carries the matched ServeMux pattern back out to middlewares
+// that sit outside a request copy, e.g. one made by a timeout middleware.
+type routeCapture struct {
+	pattern string
+	// sizeRecorded is set by NewMetricsMiddleware so NewResponseLimitMiddleware,
+	// wherever it sits in the chain, does not record the response size twice.
+	sizeRecorded bool
+}
+
+// CaptureRoute wraps a ServeMux so the pattern it matches is visible to observability
+// middlewares even when another middleware between them replaced the request with
+// r.WithContext. BaseServer installs it around its mux.
+func CaptureRoute(mux http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Deferred so the route is also recorded when the handler panics.
+		defer func() {
+			if capture, ok := r.Context().Value(routeContextKey).(*routeCapture); ok && r.Pattern != "" {
+				capture.pattern = r.Pattern
+			}
+		}()
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// withRouteCapture makes sure r carries a routeCapture for CaptureRoute to fill in.
+// Pass the returned request to the next handler and to routeLabel afterwards.
+func withRouteCapture(r *http.Request) *http.Request {
+	if _, ok := r.Context().Value(routeContextKey).(*routeCapture); ok {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), routeContextKey, &routeCapture{}))
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SLOTarget defines what a "good" request means for a route.
+type SLOTarget struct {
+	// Objective is the target ratio of good requests, e.g. 0.999.
+	Objective float64
+	// LatencyThreshold marks requests slower than this as bad. Zero disables the latency check.
+	LatencyThreshold time.Duration
+}
+
+// SLOConfig holds the configuration for the SLO middleware.
+type SLOConfig struct {
+	// Default applies to every route without an entry in Routes.
+	Default SLOTarget
+	// Routes holds per-route targets keyed by ServeMux pattern, e.g. "GET /items/{id}".
+	Routes map[string]SLOTarget
+	// Registerer is where the metrics are registered. Defaults to the global registry.
+	Registerer prometheus.Registerer
+}
+
+// NewSLOMiddleware classifies every request as good or bad against its route's SLO target
+// and exports counters suitable for multi-window burn-rate alerts. A request is bad if it
+// returns a 5xx status (reason="error") or exceeds the latency threshold (reason="latency").
+//
+// The burn rate for a window is then, e.g. for one hour:
+//
+//	sum by (route) (rate(slo_bad_requests_total[1h]))
+//	  / sum by (route) (rate(slo_requests_total[1h]))
+//	  / on (route) (1 - slo_objective_ratio)
+func NewSLOMiddleware(cfg SLOConfig) func(http.Handler) http.Handler {
+	total := registerCollector(cfg.Registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "slo_requests_total",
+		Help: "Requests classified against an SLO target.",
+	}, []string{"route"}))
+	bad := registerCollector(cfg.Registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "slo_bad_requests_total",
+		Help: "Requests that did not meet their SLO target, by reason.",
+	}, []string{"route", "reason"}))
+	objective := registerCollector(cfg.Registerer, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "slo_objective_ratio",
+		Help: "Target ratio of good requests per route.",
+	}, []string{"route"}))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := NewResponseRecorder(w)
+			r = withRouteCapture(r)
+
+			// Deferred so a panicking handler is still counted, as an error, before the
+			// panic continues to an outer recovery middleware or net/http.
+			defer func() {
+				recovered := recover()
+				elapsed := time.Since(start)
+				status := rec.status
+				if recovered != nil {
+					status = http.StatusInternalServerError
+				}
+
+				route := routeLabel(r)
+				target, ok := cfg.Routes[route]
+				if !ok {
+					target = cfg.Default
+				}
+
+				objective.WithLabelValues(route).Set(target.Objective)
+				total.WithLabelValues(route).Inc()
+				switch {
+				case status >= http.StatusInternalServerError:
+					bad.WithLabelValues(route, "error").Inc()
+				case target.LatencyThreshold > 0 && elapsed > target.LatencyThreshold:
+					bad.WithLabelValues(route, "latency").Inc()
+				}
+
+				if recovered != nil {
+					panic(recovered)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSLOMiddleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	sloMiddleware := middleware.NewSLOMiddleware(middleware.SLOConfig{
+		Default: middleware.SLOTarget{Objective: 0.99},
+		Routes: map[string]middleware.SLOTarget{
+			"GET /slow": {Objective: 0.999, LatencyThreshold: 10 * time.Millisecond},
+		},
+		Registerer: reg,
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("GET /fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	handler := sloMiddleware(mux)
+
+	for _, path := range []string{"/ok", "/ok", "/fail", "/slow"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	assert.Panics(t, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	}, "the panic is passed on to the recovery middleware")
+
+	expected := `
+# HELP slo_bad_requests_total Requests that did not meet their SLO target, by reason.
+# TYPE slo_bad_requests_total counter
+slo_bad_requests_total{reason="error",route="GET /fail"} 1
+slo_bad_requests_total{reason="error",route="GET /panic"} 1
+slo_bad_requests_total{reason="latency",route="GET /slow"} 1
+# HELP slo_requests_total Requests classified against an SLO target.
+# TYPE slo_requests_total counter
+slo_requests_total{route="GET /fail"} 1
+slo_requests_total{route="GET /ok"} 2
+slo_requests_total{route="GET /panic"} 1
+slo_requests_total{route="GET /slow"} 1
+# HELP slo_objective_ratio Target ratio of good requests per route.
+# TYPE slo_objective_ratio gauge
+slo_objective_ratio{route="GET /fail"} 0.99
+slo_objective_ratio{route="GET /ok"} 0.99
+slo_objective_ratio{route="GET /panic"} 0.99
+slo_objective_ratio{route="GET /slow"} 0.999
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"slo_requests_total", "slo_bad_requests_total", "slo_objective_ratio"))
+}
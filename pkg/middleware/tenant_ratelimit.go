@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/tenant"
+)
+
+// TenantLimit is the request budget for a single tenant.
+type TenantLimit struct {
+	// RequestsPerSecond is the sustained rate at which the budget refills.
+	RequestsPerSecond float64
+	// Burst is the maximum number of requests allowed at once.
+	Burst int
+}
+
+// TenantLimitProvider supplies per-tenant limits, e.g. from a plan or billing store.
+// It is called on every request, so implementations should cache where appropriate.
+type TenantLimitProvider interface {
+	GetLimit(ctx context.Context, tenantID string) (TenantLimit, error)
+}
+
+// TenantLimitProviderFunc adapts an ordinary function to a TenantLimitProvider.
+type TenantLimitProviderFunc func(ctx context.Context, tenantID string) (TenantLimit, error)
+
+// GetLimit calls f(ctx, tenantID).
+func (f TenantLimitProviderFunc) GetLimit(ctx context.Context, tenantID string) (TenantLimit, error) {
+	return f(ctx, tenantID)
+}
+
+// TenantRateLimitConfig holds the configuration for the tenant rate limiting middleware.
+type TenantRateLimitConfig struct {
+	// TenantFunc extracts the tenant ID from a request. Defaults to tenant.IDFromRequest,
+	// i.e. the tenant resolved by the tenant middleware, which must run first. Do not
+	// use a client-supplied header unless it has been authenticated.
+	TenantFunc func(r *http.Request) (string, bool)
+	// Provider supplies the limit for each tenant. Required.
+	Provider TenantLimitProvider
+	// Store holds the per-tenant buckets. Defaults to a MemoryRateLimitStore, which
+	// bounds the number of buckets kept in memory.
+	Store RateLimitStore
+}
+
+// NewTenantRateLimitMiddleware enforces per-tenant request quotas using a token bucket per tenant.
+// Requests over quota are rejected with 429 and Retry-After; every response carries
+// X-RateLimit-Limit and X-RateLimit-Remaining headers so clients can pace themselves.
+// It panics if cfg.Provider is nil.
+func NewTenantRateLimitMiddleware(cfg TenantRateLimitConfig) func(http.Handler) http.Handler {
+	if cfg.Provider == nil {
+		panic("middleware: TenantRateLimitConfig.Provider is required")
+	}
+	if cfg.TenantFunc == nil {
+		cfg.TenantFunc = tenant.IDFromRequest
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryRateLimitStore(0)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantID, ok := cfg.TenantFunc(r)
+			if !ok {
+				WriteError(w, r, http.StatusBadRequest, "Bad Request: Missing tenant identifier")
+				return
+			}
+
+			limit, err := cfg.Provider.GetLimit(r.Context(), tenantID)
+			if err != nil {
+				WriteError(w, r, http.StatusInternalServerError, "Internal Server Error: Unable to resolve tenant quota")
+				return
+			}
+
+			result, err := cfg.Store.Take(r.Context(), "tenant:"+tenantID, RateLimit(limit))
+			if err != nil {
+				WriteError(w, r, http.StatusInternalServerError, "Internal Server Error: Unable to check tenant quota")
+				return
+			}
+			writeRateLimitHeaders(w, limit.Burst, result.Remaining, result.RetryAfter)
+			if !result.Allowed {
+				WriteError(w, r, http.StatusTooManyRequests, "Too Many Requests: Tenant quota exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
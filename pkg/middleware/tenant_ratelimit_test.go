@@ -0,0 +1,84 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/illmade-knight/go-microservice-base/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantRateLimitMiddleware(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// "gold" tenants get a larger burst than everyone else; "broken" simulates a provider failure.
+	provider := middleware.TenantLimitProviderFunc(func(_ context.Context, tenantID string) (middleware.TenantLimit, error) {
+		switch tenantID {
+		case "gold":
+			return middleware.TenantLimit{RequestsPerSecond: 0.001, Burst: 3}, nil
+		case "broken":
+			return middleware.TenantLimit{}, errors.New("store unavailable")
+		default:
+			return middleware.TenantLimit{RequestsPerSecond: 0.001, Burst: 1}, nil
+		}
+	})
+	handler := middleware.NewTenantRateLimitMiddleware(middleware.TenantRateLimitConfig{Provider: provider})(testHandler)
+
+	doRequest := func(tenantID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tenantID != "" {
+			// By default the tenant is the one resolved by the tenant middleware.
+			req = req.WithContext(tenant.NewContext(req.Context(), tenant.Tenant{ID: tenantID}))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("Limits are applied per tenant", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			rr := doRequest("gold")
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "3", rr.Header().Get("X-RateLimit-Limit"))
+		}
+		rr := doRequest("gold")
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+		assert.Equal(t, "0", rr.Header().Get("X-RateLimit-Remaining"))
+		assert.NotEmpty(t, rr.Header().Get("Retry-After"))
+
+		// A different tenant has its own budget.
+		assert.Equal(t, http.StatusOK, doRequest("basic").Code)
+		assert.Equal(t, http.StatusTooManyRequests, doRequest("basic").Code)
+	})
+
+	t.Run("Missing tenant is rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, doRequest("").Code)
+	})
+
+	t.Run("Provider failure returns 500", func(t *testing.T) {
+		assert.Equal(t, http.StatusInternalServerError, doRequest("broken").Code)
+	})
+}
+
+func TestTenantRateLimitMiddleware_Construction(t *testing.T) {
+	assert.Panics(t, func() { middleware.NewTenantRateLimitMiddleware(middleware.TenantRateLimitConfig{}) })
+
+	provider := middleware.TenantLimitProviderFunc(func(context.Context, string) (middleware.TenantLimit, error) {
+		return middleware.TenantLimit{RequestsPerSecond: 1, Burst: 1}, nil
+	})
+	handler := middleware.NewTenantRateLimitMiddleware(middleware.TenantRateLimitConfig{Provider: provider})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// A client-supplied header is not trusted by default.
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Tenant-ID", "someone-else")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
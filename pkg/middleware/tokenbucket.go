@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a minimal, thread-safe token bucket used by the rate limiting middlewares.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64 // tokens added per second
+	burst  float64 // bucket capacity
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates a full bucket.
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// setLimit updates the rate and capacity, keeping the current token count within the new capacity.
+func (b *tokenBucket) setLimit(rate float64, burst int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rate = rate
+	b.burst = float64(burst)
+	b.tokens = math.Min(b.tokens, b.burst)
+}
+
+// take attempts to consume one token. It reports whether the request is allowed,
+// how many whole tokens remain, and how long until the next token is available.
+func (b *tokenBucket) take(now time.Time) (allowed bool, remaining int, wait time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, int(b.tokens), 0
+	}
+	if b.rate <= 0 {
+		return false, 0, time.Hour
+	}
+	wait = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	return false, 0, wait
+}
+
+// writeRateLimitHeaders sets the standard quota headers on a response.
+func writeRateLimitHeaders(w http.ResponseWriter, limit, remaining int, wait time.Duration) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	if wait > 0 {
+		seconds := strconv.Itoa(int(math.Ceil(wait.Seconds())))
+		w.Header().Set("X-RateLimit-Reset", seconds)
+		w.Header().Set("Retry-After", seconds)
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies spans created by this package.
+const tracerName = "github.com/illmade-knight/go-microservice-base/pkg/middleware"
+
+// Propagator is the W3C Trace Context and Baggage propagator used for incoming and
+// outgoing requests, independent of whatever global propagator is installed.
+var Propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// NewTracingMiddleware starts a server span for every request, continuing the trace from
+// an incoming W3C traceparent header. Spans are created with the global TracerProvider,
+// which the BaseServer WithTracing option configures; without it they are no-ops.
+// Spans are named after the matched ServeMux pattern, e.g. "GET /items/{id}".
+func NewTracingMiddleware(serviceName string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tracer := otel.GetTracerProvider().Tracer(tracerName)
+			ctx := Propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("service.name", serviceName),
+					attribute.String("http.request.method", r.Method),
+					attribute.String("url.path", r.URL.Path),
+					attribute.String("user_agent.original", r.UserAgent()),
+				))
+			defer span.End()
+
+			rec := NewResponseRecorder(w)
+			req := withRouteCapture(r.WithContext(ctx))
+			next.ServeHTTP(rec, req)
+
+			route := routeLabel(req)
+			span.SetName(route)
+			span.SetAttributes(
+				attribute.String("http.route", route),
+				attribute.Int("http.response.status_code", rec.status),
+			)
+			if rec.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(rec.status))
+			}
+		})
+	}
+}
+
+// GetTraceIDFromContext returns the hex trace ID of the span in ctx, if any.
+func GetTraceIDFromContext(ctx context.Context) (string, bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return "", false
+	}
+	return sc.TraceID().String(), true
+}
+
+// GetSpanIDFromContext returns the hex span ID of the span in ctx, if any.
+func GetSpanIDFromContext(ctx context.Context) (string, bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasSpanID() {
+		return "", false
+	}
+	return sc.SpanID().String(), true
+}
+
+// LoggerWithTrace adds trace_id and span_id fields to logger when ctx carries a span,
+// so log lines can be correlated with traces:
+//
+//	log := middleware.LoggerWithTrace(r.Context(), s.Logger)
+func LoggerWithTrace(ctx context.Context, logger zerolog.Logger) zerolog.Logger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return logger
+	}
+	return logger.With().Str("trace_id", sc.TraceID().String()).Str("span_id", sc.SpanID().String()).Logger()
+}
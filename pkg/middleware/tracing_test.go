@@ -0,0 +1,56 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	var traceID, spanID string
+	var logged bytes.Buffer
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		traceID, _ = middleware.GetTraceIDFromContext(r.Context())
+		spanID, _ = middleware.GetSpanIDFromContext(r.Context())
+		logger := middleware.LoggerWithTrace(r.Context(), zerolog.New(&logged))
+		logger.Info().Msg("fetching item")
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	handler := middleware.NewTracingMiddleware("test-service")(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /items/{id}", span.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String(), "the incoming traceparent is the parent")
+	assert.Equal(t, codes.Error, span.Status().Code)
+
+	assert.Equal(t, span.SpanContext().TraceID().String(), traceID)
+	assert.Equal(t, span.SpanContext().SpanID().String(), spanID)
+	assert.Contains(t, logged.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+
+	_, ok := middleware.GetTraceIDFromContext(req.Context())
+	assert.False(t, ok)
+}
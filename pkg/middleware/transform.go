@@ -0,0 +1,314 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// RequestTransformer rewrites an incoming request body before the handler reads it,
+// e.g. upgrading a legacy payload. It should wrap body rather than read it fully,
+// so that large uploads keep streaming.
+type RequestTransformer func(r *http.Request, body io.Reader) (io.Reader, error)
+
+// ResponseTransformer rewrites an outgoing response body, e.g. redacting fields.
+// It is invoked once the handler has set its headers and returns a writer that
+// transforms everything written to it into dst, on the handler's goroutine. Close is
+// called after the handler returns and must flush any remaining output; writers that
+// buffer should also implement http.Flusher so handler flushes reach the client.
+// Transformers that do not apply to a response (e.g. wrong Content-Type) should
+// return PassThrough(dst).
+type ResponseTransformer func(r *http.Request, header http.Header, dst io.Writer) io.WriteCloser
+
+// TransformConfig holds the transformers applied around a handler.
+type TransformConfig struct {
+	// Request transformers are applied in order to the request body.
+	Request []RequestTransformer
+	// Response transformers are applied in order; the first sees the handler's raw output.
+	Response []ResponseTransformer
+}
+
+// NewTransformMiddleware applies body transformers around a handler so that gateway-style
+// rewriting does not require forking handlers. Because transformed bodies may change
+// length, Content-Length is dropped on both the request and the response.
+func NewTransformMiddleware(cfg TransformConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(cfg.Request) > 0 && r.Body != nil && r.Body != http.NoBody {
+				body := io.Reader(r.Body)
+				for _, transform := range cfg.Request {
+					var err error
+					if body, err = transform(r, body); err != nil {
+						WriteError(w, r, http.StatusBadRequest, fmt.Sprintf("Bad Request: %s", err.Error()))
+						return
+					}
+				}
+				r = r.Clone(r.Context())
+				r.Body = readCloser{Reader: body, Closer: r.Body}
+				r.ContentLength = -1
+				r.Header.Del("Content-Length")
+			}
+
+			if len(cfg.Response) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tw := &transformWriter{ResponseWriter: w, r: r, transformers: cfg.Response}
+			next.ServeHTTP(tw, r)
+			// Headers are already sent, so a transformer error can only truncate the body.
+			_ = tw.close()
+		})
+	}
+}
+
+// PassThrough returns a ResponseTransformer result that writes dst unchanged.
+func PassThrough(dst io.Writer) io.WriteCloser {
+	return nopWriteCloser{dst}
+}
+
+// RedactJSONFields returns a ResponseTransformer that replaces the values of the named
+// object keys, at any depth, with "[REDACTED]" in application/json responses.
+// The body is scanned as it is written, on the handler's goroutine, so streamed and
+// newline-delimited JSON are redacted without buffering the whole body.
+func RedactJSONFields(fields ...string) ResponseTransformer {
+	redact := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		redact[f] = true
+	}
+
+	return func(_ *http.Request, header http.Header, dst io.Writer) io.WriteCloser {
+		if !strings.HasPrefix(header.Get("Content-Type"), "application/json") {
+			return PassThrough(dst)
+		}
+		return &jsonRedactor{dst: dst, redact: redact}
+	}
+}
+
+// jsonRedactor copies JSON from Write to dst, replacing the values of redacted keys.
+// It keeps only the scanner state between writes, never the body itself.
+type jsonRedactor struct {
+	dst    io.Writer
+	redact map[string]bool
+	out    []byte
+
+	stack     []byte // '{' or '[' for every open container
+	expectKey bool   // the next string in the current object is a key
+	inString  bool
+	escaped   bool
+	isKey     bool   // the current string is an object key
+	key       []byte // raw bytes of the key being read
+
+	// State while replacing the value that follows a redacted key.
+	redactNext bool // a redacted key was read; its value follows the ':'
+	skipping   bool
+	skipStart  bool // the first byte of the skipped value has not been seen yet
+	skipDepth  int  // nesting depth of a skipped object or array
+	skipScalar bool
+	skipString bool
+	skipEscape bool
+}
+
+func (j *jsonRedactor) Write(b []byte) (int, error) {
+	j.out = j.out[:0]
+	for _, c := range b {
+		if j.skipping && j.skip(c) {
+			continue
+		}
+		j.scan(c)
+	}
+	if _, err := j.dst.Write(j.out); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+// Close has nothing to flush: every byte is written through as soon as it is scanned.
+func (j *jsonRedactor) Close() error {
+	return nil
+}
+
+// scan copies c, tracking enough structure to recognise object keys.
+func (j *jsonRedactor) scan(c byte) {
+	j.out = append(j.out, c)
+	if j.inString {
+		switch {
+		case j.escaped:
+			j.escaped = false
+		case c == '\\':
+			j.escaped = true
+		case c == '"':
+			j.inString = false
+			if j.isKey {
+				j.isKey = false
+				var key string
+				if json.Unmarshal(append(append([]byte{'"'}, j.key...), '"'), &key) == nil && j.redact[key] {
+					j.redactNext = true
+				}
+				j.key = j.key[:0]
+			}
+			return
+		}
+		if j.isKey {
+			j.key = append(j.key, c)
+		}
+		return
+	}
+
+	switch c {
+	case '"':
+		j.inString = true
+		j.isKey = j.expectKey && j.top() == '{'
+		j.expectKey = false
+	case '{', '[':
+		j.stack = append(j.stack, c)
+		j.expectKey = c == '{'
+	case '}', ']':
+		if len(j.stack) > 0 {
+			j.stack = j.stack[:len(j.stack)-1]
+		}
+	case ',':
+		j.expectKey = j.top() == '{'
+	case ':':
+		if j.redactNext {
+			j.redactNext = false
+			j.skipping, j.skipStart = true, true
+			j.out = append(j.out, `"[REDACTED]"`...)
+		}
+	}
+}
+
+// skip consumes c as part of a redacted value. It returns false once the value has
+// ended and c belongs to what follows, which must then be scanned normally.
+func (j *jsonRedactor) skip(c byte) bool {
+	if j.skipStart {
+		switch c {
+		case ' ', '\t', '\r', '\n':
+			return true
+		case '"':
+			j.skipString = true
+		case '{', '[':
+			j.skipDepth = 1
+		default:
+			j.skipScalar = true
+		}
+		j.skipStart = false
+		return true
+	}
+
+	switch {
+	case j.skipString:
+		switch {
+		case j.skipEscape:
+			j.skipEscape = false
+		case c == '\\':
+			j.skipEscape = true
+		case c == '"':
+			j.skipString = false
+			j.skipping = j.skipDepth > 0
+		}
+		return true
+	case j.skipDepth > 0:
+		switch c {
+		case '"':
+			j.skipString = true
+		case '{', '[':
+			j.skipDepth++
+		case '}', ']':
+			j.skipDepth--
+			j.skipping = j.skipDepth > 0
+		}
+		return true
+	default: // a number, true, false or null ends at the first delimiter
+		switch c {
+		case ',', '}', ']', ' ', '\t', '\r', '\n':
+			j.skipScalar, j.skipping = false, false
+			return false
+		}
+		return true
+	}
+}
+
+// top returns the innermost open container, or 0 at the top level.
+func (j *jsonRedactor) top() byte {
+	if len(j.stack) == 0 {
+		return 0
+	}
+	return j.stack[len(j.stack)-1]
+}
+
+// transformWriter builds the response transformer chain when the handler commits its headers.
+type transformWriter struct {
+	http.ResponseWriter
+	r            *http.Request
+	transformers []ResponseTransformer
+	chain        []io.WriteCloser // outermost first
+	wroteHeader  bool
+}
+
+func (w *transformWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.Header().Del("Content-Length")
+
+	var dst io.Writer = w.ResponseWriter
+	w.chain = make([]io.WriteCloser, len(w.transformers))
+	for i := len(w.transformers) - 1; i >= 0; i-- {
+		w.chain[i] = w.transformers[i](w.r, w.Header(), dst)
+		dst = w.chain[i]
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *transformWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.chain[0].Write(b)
+}
+
+// close flushes the chain from the outermost transformer inwards.
+func (w *transformWriter) close() error {
+	var errs []error
+	for _, wc := range w.chain {
+		errs = append(errs, wc.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Flush pushes output through every transformer that buffers, outermost first, and
+// then flushes the underlying writer, so streaming handlers keep streaming.
+func (w *transformWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	for _, wc := range w.chain {
+		if f, ok := wc.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *transformWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
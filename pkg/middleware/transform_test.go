@@ -0,0 +1,111 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/illmade-knight/go-microservice-base/pkg/response"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformMiddleware_RedactJSONFields(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.WriteJSON(w, http.StatusOK, map[string]any{
+			"user":  map[string]any{"name": "ada", "password": "hunter2"},
+			"items": []any{map[string]any{"token": "abc", "id": 1}},
+		})
+	})
+	handler := middleware.NewTransformMiddleware(middleware.TransformConfig{
+		Response: []middleware.ResponseTransformer{middleware.RedactJSONFields("password", "token")},
+	})(testHandler)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t,
+		`{"items":[{"id":1,"token":"[REDACTED]"}],"user":{"name":"ada","password":"[REDACTED]"}}`,
+		rr.Body.String())
+}
+
+func TestTransformMiddleware_RedactJSONFieldsStreaming(t *testing.T) {
+	body := `{"a":1,"password":{"nested":"}\\\"]","list":[1,{"x":"{"}]},"b":"ok"}` + "\n" +
+		`{"token" : 12.5e3 ,"c":[true,null],"d":{"token":"s\"ecret"}}` + "\n" +
+		`{"password":null}`
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// One byte per write: redaction must not depend on how the body is split.
+		for i := 0; i < len(body); i++ {
+			_, _ = w.Write([]byte{body[i]})
+			w.(http.Flusher).Flush()
+		}
+	})
+	handler := middleware.NewTransformMiddleware(middleware.TransformConfig{
+		Response: []middleware.ResponseTransformer{middleware.RedactJSONFields("password", "token")},
+	})(testHandler)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.True(t, rr.Flushed, "Flush should reach the underlying writer")
+	lines := strings.Split(rr.Body.String(), "\n")
+	require.Len(t, lines, 3)
+	assert.JSONEq(t, `{"a":1,"password":"[REDACTED]","b":"ok"}`, lines[0])
+	assert.JSONEq(t, `{"token":"[REDACTED]","c":[true,null],"d":{"token":"[REDACTED]"}}`, lines[1])
+	assert.JSONEq(t, `{"password":"[REDACTED]"}`, lines[2])
+}
+
+func TestTransformMiddleware_NonJSONPassesThrough(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(`{"password":"visible"}`))
+	})
+	handler := middleware.NewTransformMiddleware(middleware.TransformConfig{
+		Response: []middleware.ResponseTransformer{middleware.RedactJSONFields("password")},
+	})(testHandler)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, `{"password":"visible"}`, rr.Body.String())
+}
+
+func TestTransformMiddleware_RequestUpgrade(t *testing.T) {
+	var received string
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// Rename a legacy field without buffering the body.
+	upgrade := func(_ *http.Request, body io.Reader) (io.Reader, error) {
+		pr, pw := io.Pipe()
+		go func() {
+			b, err := io.ReadAll(body)
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+			_, _ = pw.Write([]byte(strings.ReplaceAll(string(b), `"user_name"`, `"username"`)))
+			_ = pw.Close()
+		}()
+		return pr, nil
+	}
+	handler := middleware.NewTransformMiddleware(middleware.TransformConfig{
+		Request: []middleware.RequestTransformer{upgrade},
+	})(testHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"user_name":"ada"}`))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	assert.Equal(t, `{"username":"ada"}`, received)
+}
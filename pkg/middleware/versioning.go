@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// apiVersionContextKey is the key used to store the resolved API version.
+const apiVersionContextKey contextKey = "apiVersion"
+
+// VersionConfig holds the configuration for the API version router.
+type VersionConfig struct {
+	// Versions maps a version name (e.g. "v1") to the handler set serving it.
+	// Handlers are registered without the version prefix.
+	Versions map[string]http.Handler
+	// Default is the version used when a request does not specify one.
+	Default string
+	// Registerer is where the per-version request counter is registered.
+	// Defaults to the global registry served by BaseServer's /metrics endpoint.
+	Registerer prometheus.Registerer
+}
+
+// NewVersionRouter returns a handler that dispatches each request to a versioned handler set.
+// The version is resolved, in order, from:
+//  1. a path prefix, e.g. /v2/items (the prefix is stripped before dispatch),
+//  2. a version parameter on the Accept header, e.g. "application/json; version=2",
+//  3. the configured default.
+//
+// The resolved version is available to handlers via GetAPIVersionFromContext and is
+// counted in http_api_version_requests_total to track deprecation progress.
+func NewVersionRouter(cfg VersionConfig) http.Handler {
+	requests := registerCollector(cfg.Registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_api_version_requests_total",
+		Help: "Requests served per API version.",
+	}, []string{"version"}))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, rest, fromPath := versionFromPath(r.URL.Path, cfg.Versions)
+		if !fromPath {
+			var specified bool
+			version, specified = versionFromAccept(r.Header.Get("Accept"))
+			if !specified {
+				version = cfg.Default
+			}
+		}
+
+		handler, ok := cfg.Versions[version]
+		if !ok {
+			WriteError(w, r, http.StatusNotAcceptable, "Not Acceptable: Unsupported API version")
+			return
+		}
+		requests.WithLabelValues(version).Inc()
+
+		ctx := context.WithValue(r.Context(), apiVersionContextKey, version)
+		r = r.WithContext(ctx)
+		if fromPath {
+			u := *r.URL
+			u.Path = rest
+			u.RawPath = ""
+			r.URL = &u
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// GetAPIVersionFromContext retrieves the API version resolved by the version router.
+func GetAPIVersionFromContext(ctx context.Context) (string, bool) {
+	version, ok := ctx.Value(apiVersionContextKey).(string)
+	return version, ok
+}
+
+// versionFromPath checks whether the first path segment names a known version,
+// returning the version and the remaining path.
+func versionFromPath(path string, versions map[string]http.Handler) (string, string, bool) {
+	segment, rest, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if _, ok := versions[segment]; !ok {
+		return "", "", false
+	}
+	return segment, "/" + rest, true
+}
+
+// versionFromAccept reads the "version" parameter from the first Accept media range
+// carrying one. Bare numbers are normalized, so "2" resolves to "v2".
+func versionFromAccept(accept string) (string, bool) {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		if version := params["version"]; version != "" {
+			if !strings.HasPrefix(version, "v") {
+				version = "v" + version
+			}
+			return version, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,66 @@
+package middleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionRouter(t *testing.T) {
+	// Each handler set echoes the version it belongs to, the context version and the path it saw.
+	versionedHandler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			version, _ := middleware.GetAPIVersionFromContext(r.Context())
+			_, _ = fmt.Fprintf(w, "%s|%s|%s", name, version, r.URL.Path)
+		})
+	}
+
+	reg := prometheus.NewRegistry()
+	router := middleware.NewVersionRouter(middleware.VersionConfig{
+		Versions: map[string]http.Handler{
+			"v1": versionedHandler("v1"),
+			"v2": versionedHandler("v2"),
+		},
+		Default:    "v1",
+		Registerer: reg,
+	})
+
+	testCases := []struct {
+		name         string
+		path         string
+		accept       string
+		expectedCode int
+		expectedBody string
+	}{
+		{name: "Path prefix", path: "/v2/items", expectedCode: http.StatusOK, expectedBody: "v2|v2|/items"},
+		{name: "Accept header", path: "/items", accept: "application/json; version=2", expectedCode: http.StatusOK, expectedBody: "v2|v2|/items"},
+		{name: "Default version", path: "/items", expectedCode: http.StatusOK, expectedBody: "v1|v1|/items"},
+		{name: "Path prefix wins over Accept", path: "/v1/items", accept: "application/json; version=2", expectedCode: http.StatusOK, expectedBody: "v1|v1|/items"},
+		{name: "Unknown Accept version", path: "/items", accept: "application/json; version=9", expectedCode: http.StatusNotAcceptable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedCode, rr.Code)
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, rr.Body.String())
+			}
+		})
+	}
+
+	assert.Equal(t, 2, testutil.CollectAndCount(reg, "http_api_version_requests_total"), "one series per version served")
+}
@@ -0,0 +1,172 @@
+package outbound
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// ClientConfig configures NewClient.
+type ClientConfig struct {
+	// TokenSource authenticates requests with a bearer token. Nil sends no token;
+	// use NewMetadataIDTokenSource on Cloud Run or StaticTokenSource locally.
+	TokenSource TokenSource
+	// Transport performs the requests, e.g. one from NewEgressTransport.
+	// Defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+	// Timeout bounds each call including retries. Defaults to 30s.
+	Timeout time.Duration
+	// MaxRetries is the number of retries after a network error or 5xx response.
+	// Defaults to 2; set a negative value to disable retries.
+	MaxRetries int
+	// InitialBackoff is the delay before the first retry; it doubles per retry. Defaults to 100ms.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the retry delay. Defaults to 2s.
+	MaxBackoff time.Duration
+}
+
+// NewClient returns an http.Client for service-to-service calls. Every request:
+//   - carries a bearer token from cfg.TokenSource, if set, except on redirects to another host;
+//   - forwards the request ID and W3C trace context found in its context, so pass the
+//     incoming request's context with http.NewRequestWithContext;
+//   - is retried with jittered exponential backoff on network errors and 5xx responses,
+//     provided it is idempotent (GET, HEAD, OPTIONS, PUT, DELETE or an Idempotency-Key
+//     header) and its body can be replayed.
+func NewClient(cfg ClientConfig) *http.Client {
+	if cfg.Transport == nil {
+		cfg.Transport = http.DefaultTransport
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 2
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = 100 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 2 * time.Second
+	}
+
+	var transport http.RoundTripper = &retryTransport{base: cfg.Transport, cfg: cfg}
+	transport = &propagationTransport{base: transport}
+	if cfg.TokenSource != nil {
+		transport = &authTransport{base: transport, tokens: cfg.TokenSource}
+	}
+	return &http.Client{Transport: transport, Timeout: cfg.Timeout}
+}
+
+// authTransport adds a bearer token to each request.
+type authTransport struct {
+	base   http.RoundTripper
+	tokens TokenSource
+}
+
+// RoundTrip implements http.RoundTripper. The token is only sent to the host of the
+// request the caller made: like http.Client does for an Authorization header, it is
+// withheld when following a redirect to another host.
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != originalHost(req) {
+		return t.base.RoundTrip(req)
+	}
+	token, err := t.tokens.Token(req.Context())
+	if err != nil {
+		closeBody(req)
+		return nil, fmt.Errorf("outbound: failed to get token: %w", err)
+	}
+	// RoundTrippers must not modify the caller's request.
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
+
+// originalHost returns the host of the first request in a redirect chain.
+func originalHost(req *http.Request) string {
+	for req.Response != nil && req.Response.Request != nil {
+		req = req.Response.Request
+	}
+	return req.URL.Host
+}
+
+// propagationTransport forwards the request ID and trace context from the request context.
+type propagationTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *propagationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if id, ok := middleware.GetRequestIDFromContext(req.Context()); ok && req.Header.Get(middleware.RequestIDHeader) == "" {
+		req.Header.Set(middleware.RequestIDHeader, id)
+	}
+	middleware.Propagator.Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	return t.base.RoundTrip(req)
+}
+
+// retryTransport retries idempotent requests on network errors and 5xx responses.
+type retryTransport struct {
+	base http.RoundTripper
+	cfg  ClientConfig
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.cfg.MaxRetries < 0 || !retryable(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	backoff := t.cfg.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt >= t.cfg.MaxRetries || (err == nil && resp.StatusCode < http.StatusInternalServerError) {
+			return resp, err
+		}
+		if err == nil {
+			// Drain so the connection can be reused by the retry.
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+		}
+
+		wait := time.Duration(rand.Int64N(int64(backoff))) + backoff/2
+		select {
+		case <-time.After(wait):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		backoff = min(backoff*2, t.cfg.MaxBackoff)
+
+		if req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("outbound: failed to rewind request body: %w", err)
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryable reports whether req may safely be sent more than once.
+func retryable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// closeBody closes the request body, as RoundTrip must even when it fails early.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
@@ -0,0 +1,187 @@
+package outbound_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/illmade-knight/go-microservice-base/pkg/outbound"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func fastRetries(cfg outbound.ClientConfig) outbound.ClientConfig {
+	cfg.InitialBackoff = time.Millisecond
+	cfg.MaxBackoff = 2 * time.Millisecond
+	return cfg
+}
+
+func TestClient_PropagatesIdentityAndContext(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	client := outbound.NewClient(outbound.ClientConfig{TokenSource: outbound.StaticTokenSource("local-token")})
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled,
+	}))
+	ctx = middleware.ContextWithRequestID(ctx, "req-123")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, "Bearer local-token", got.Get("Authorization"))
+	assert.Equal(t, "req-123", got.Get(middleware.RequestIDHeader))
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", got.Get("traceparent"))
+	assert.Empty(t, req.Header.Get("Authorization"), "caller's request must not be modified")
+}
+
+func TestClient_Retries(t *testing.T) {
+	newServer := func(failures int32) (*httptest.Server, *atomic.Int32) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if calls.Add(1) <= failures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write(body)
+		}))
+		t.Cleanup(server.Close)
+		return server, &calls
+	}
+
+	t.Run("GET is retried until it succeeds", func(t *testing.T) {
+		server, calls := newServer(2)
+		resp, err := outbound.NewClient(fastRetries(outbound.ClientConfig{})).Get(server.URL)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.EqualValues(t, 3, calls.Load())
+	})
+
+	t.Run("gives up after MaxRetries", func(t *testing.T) {
+		server, calls := newServer(10)
+		resp, err := outbound.NewClient(fastRetries(outbound.ClientConfig{MaxRetries: 1})).Get(server.URL)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("POST is not retried", func(t *testing.T) {
+		server, calls := newServer(1)
+		resp, err := outbound.NewClient(fastRetries(outbound.ClientConfig{})).Post(server.URL, "text/plain", strings.NewReader("x"))
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.EqualValues(t, 1, calls.Load())
+	})
+
+	t.Run("POST with an idempotency key replays its body", func(t *testing.T) {
+		server, calls := newServer(1)
+		req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("payload"))
+		require.NoError(t, err)
+		req.Header.Set("Idempotency-Key", "abc")
+		resp, err := outbound.NewClient(fastRetries(outbound.ClientConfig{})).Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "payload", string(body))
+		assert.EqualValues(t, 2, calls.Load())
+	})
+}
+
+func testIDToken(exp time.Time) string {
+	enc := base64.RawURLEncoding.EncodeToString
+	return enc([]byte(`{"alg":"RS256"}`)) + "." + enc([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix()))) + ".sig"
+}
+
+func TestMetadataIDTokenSource(t *testing.T) {
+	var fetches atomic.Int32
+	lifetime := time.Hour
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" || r.URL.Query().Get("audience") != "https://target.run.app" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		fetches.Add(1)
+		_, _ = io.WriteString(w, testIDToken(time.Now().Add(lifetime)))
+	}))
+	defer server.Close()
+
+	source := outbound.NewMetadataIDTokenSource("https://target.run.app")
+	source.Endpoint = server.URL
+
+	first, err := source.Token(context.Background())
+	require.NoError(t, err)
+	second, err := source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.EqualValues(t, 1, fetches.Load(), "a fresh token should be cached")
+
+	// A token close to expiry is refreshed.
+	lifetime = time.Minute
+	fresh := outbound.NewMetadataIDTokenSource("https://target.run.app")
+	fresh.Endpoint = server.URL
+	_, err = fresh.Token(context.Background())
+	require.NoError(t, err)
+	_, err = fresh.Token(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, fetches.Load())
+
+	wrong := outbound.NewMetadataIDTokenSource("https://other")
+	wrong.Endpoint = server.URL
+	_, err = wrong.Token(context.Background())
+	assert.Error(t, err)
+}
+
+func TestClient_TokenNotSentAcrossRedirects(t *testing.T) {
+	var leaked string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		leaked = r.Header.Get("Authorization")
+	}))
+	defer other.Close()
+
+	var sameHostAuth string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/elsewhere":
+			http.Redirect(w, r, other.URL, http.StatusFound)
+		case "/moved":
+			http.Redirect(w, r, "/final", http.StatusFound)
+		case "/final":
+			sameHostAuth = r.Header.Get("Authorization")
+		}
+	}))
+	defer target.Close()
+
+	client := outbound.NewClient(outbound.ClientConfig{TokenSource: outbound.StaticTokenSource("secret-token")})
+
+	resp, err := client.Get(target.URL + "/elsewhere")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Empty(t, leaked, "the token must not follow a redirect to another host")
+
+	resp, err = client.Get(target.URL + "/moved")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, "Bearer secret-token", sameHostAuth)
+}
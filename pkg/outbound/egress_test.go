@@ -0,0 +1,71 @@
+package outbound_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/outbound"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEgressTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	get := func(t *testing.T, policy outbound.EgressPolicy, url string) error {
+		t.Helper()
+		transport, err := outbound.NewEgressTransport(policy)
+		require.NoError(t, err)
+		client := &http.Client{Transport: transport, Timeout: 2 * time.Second}
+		resp, err := client.Get(url)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	t.Run("no restrictions", func(t *testing.T) {
+		assert.NoError(t, get(t, outbound.EgressPolicy{}, server.URL))
+	})
+
+	t.Run("metadata endpoints are always blocked", func(t *testing.T) {
+		err := get(t, outbound.EgressPolicy{}, "http://169.254.169.254/computeMetadata/v1/")
+		assert.ErrorIs(t, err, outbound.ErrEgressDenied)
+
+		err = get(t, outbound.EgressPolicy{}, "http://[fd20:ce::254]/computeMetadata/v1/")
+		assert.ErrorIs(t, err, outbound.ErrEgressDenied, "GCP's IPv6 metadata address")
+	})
+
+	t.Run("broad allowed CIDRs do not unblock metadata endpoints", func(t *testing.T) {
+		policy := outbound.EgressPolicy{AllowedCIDRs: []string{"0.0.0.0/0", "::/0"}}
+		err := get(t, policy, "http://169.254.169.254/computeMetadata/v1/")
+		assert.ErrorIs(t, err, outbound.ErrEgressDenied)
+	})
+
+	t.Run("private networks blocked on request", func(t *testing.T) {
+		err := get(t, outbound.EgressPolicy{BlockPrivateNetworks: true}, server.URL)
+		assert.ErrorIs(t, err, outbound.ErrEgressDenied)
+	})
+
+	t.Run("allowed CIDR overrides private block", func(t *testing.T) {
+		policy := outbound.EgressPolicy{AllowedCIDRs: []string{"127.0.0.0/8"}, BlockPrivateNetworks: true}
+		assert.NoError(t, get(t, policy, server.URL))
+	})
+
+	t.Run("host allowlist", func(t *testing.T) {
+		assert.NoError(t, get(t, outbound.EgressPolicy{AllowedHosts: []string{"127.0.0.1"}}, server.URL))
+
+		err := get(t, outbound.EgressPolicy{AllowedHosts: []string{"*.example.com"}}, server.URL)
+		assert.ErrorIs(t, err, outbound.ErrEgressDenied)
+	})
+
+	t.Run("invalid CIDR", func(t *testing.T) {
+		_, err := outbound.NewEgressTransport(outbound.EgressPolicy{AllowedCIDRs: []string{"not-a-cidr"}})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,129 @@
+package outbound
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metadataIdentityURL is the GCE/Cloud Run metadata endpoint that mints ID tokens.
+const metadataIdentityURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/identity"
+
+// tokenRefreshMargin renews cached tokens this long before they expire.
+const tokenRefreshMargin = 5 * time.Minute
+
+// TokenSource supplies bearer tokens for outbound requests.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticTokenSource always returns the same token, e.g. one minted with
+// `gcloud auth print-identity-token` for local development.
+type StaticTokenSource string
+
+// Token implements TokenSource.
+func (s StaticTokenSource) Token(context.Context) (string, error) {
+	return string(s), nil
+}
+
+// MetadataIDTokenSource fetches Google-signed OIDC ID tokens for an audience from the
+// metadata server available on Cloud Run and GCE. Tokens are cached until shortly
+// before they expire.
+type MetadataIDTokenSource struct {
+	Audience string
+	// Client fetches tokens. Defaults to a client with a 5s timeout. It must be able to
+	// reach the metadata server, so do not give it an egress-restricted transport.
+	Client *http.Client
+	// Endpoint overrides the metadata URL, for tests.
+	Endpoint string
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewMetadataIDTokenSource returns a TokenSource minting ID tokens for audience,
+// which for Cloud Run is the URL of the receiving service.
+func NewMetadataIDTokenSource(audience string) *MetadataIDTokenSource {
+	return &MetadataIDTokenSource{Audience: audience}
+}
+
+// Token implements TokenSource, returning a cached token while it is still fresh.
+func (s *MetadataIDTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Until(s.expires) > tokenRefreshMargin {
+		return s.token, nil
+	}
+
+	token, err := s.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	expires, err := tokenExpiry(token)
+	if err != nil {
+		return "", err
+	}
+	s.token, s.expires = token, expires
+	return token, nil
+}
+
+// fetch requests a new ID token from the metadata server.
+func (s *MetadataIDTokenSource) fetch(ctx context.Context) (string, error) {
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = metadataIdentityURL
+	}
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		endpoint+"?audience="+url.QueryEscape(s.Audience)+"&format=full", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("outbound: failed to fetch ID token: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return "", fmt.Errorf("outbound: failed to read ID token: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("outbound: metadata server returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// tokenExpiry reads the exp claim of a JWT without verifying it; the token came
+// straight from the issuer and is only inspected to schedule its refresh.
+func tokenExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("outbound: ID token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("outbound: failed to decode ID token: %w", err)
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, fmt.Errorf("outbound: ID token has no expiry")
+	}
+	return time.Unix(claims.Exp, 0), nil
+}
@@ -0,0 +1,55 @@
+package quota
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+)
+
+// PrincipalFunc extracts the billable principal (user, tenant, API key) from a request.
+type PrincipalFunc func(r *http.Request) (string, bool)
+
+// UserPrincipal uses the authenticated user ID set by the JWT middleware.
+func UserPrincipal(r *http.Request) (string, bool) {
+	return middleware.GetUserIDFromContext(r.Context())
+}
+
+// Middleware meters every request and its response bytes against the principal
+// returned by principalFunc, rejecting requests with 429 once the request quota
+// is exhausted. Requests without a principal pass through unmetered.
+// If principalFunc is nil, UserPrincipal is used.
+func (m *Meter) Middleware(principalFunc PrincipalFunc) func(http.Handler) http.Handler {
+	if principalFunc == nil {
+		principalFunc = UserPrincipal
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := principalFunc(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			remaining, limited, err := m.Consume(r.Context(), principal, UnitRequests, 1)
+			if err != nil {
+				if errors.Is(err, ErrQuotaExceeded) {
+					middleware.WriteError(w, r, http.StatusTooManyRequests, "Too Many Requests: Quota exceeded")
+					return
+				}
+				m.logger.Error().Err(err).Str("principal", principal).Msg("Quota check failed")
+				middleware.WriteError(w, r, http.StatusInternalServerError, "Internal Server Error: Unable to check quota")
+				return
+			}
+			if limited {
+				w.Header().Set("X-Quota-Remaining", strconv.FormatInt(remaining, 10))
+			}
+
+			rec := middleware.NewResponseRecorder(w)
+			next.ServeHTTP(rec, r)
+			m.Add(principal, UnitBytes, rec.Written())
+		})
+	}
+}
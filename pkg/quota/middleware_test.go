@@ -0,0 +1,68 @@
+package quota_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/middleware"
+	"github.com/illmade-knight/go-microservice-base/pkg/quota"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMeter_Middleware(t *testing.T) {
+	var lookups atomic.Int32
+	provider := quota.ProviderFunc(func(_ context.Context, _ string, unit quota.Unit) (int64, bool, error) {
+		lookups.Add(1)
+		return 2, unit == quota.UnitRequests, nil
+	})
+	meter := quota.NewMeter(quota.Config{Provider: provider}, zerolog.Nop())
+
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	})
+	handler := meter.Middleware(nil)(testHandler)
+
+	doRequest := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if userID != "" {
+			req = req.WithContext(middleware.ContextWithUserID(req.Context(), userID))
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := doRequest("user-123")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("X-Quota-Remaining"))
+	assert.Equal(t, int32(1), lookups.Load(), "the provider should be queried once per request")
+	assert.Equal(t, http.StatusOK, doRequest("user-123").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest("user-123").Code)
+
+	assert.Equal(t, int64(2), meter.Usage("user-123", quota.UnitRequests))
+	assert.Equal(t, int64(10), meter.Usage("user-123", quota.UnitBytes))
+
+	// Anonymous requests are not metered.
+	assert.Equal(t, http.StatusOK, doRequest("").Code)
+}
+
+func TestMeter_MiddlewareKeepsFlusher(t *testing.T) {
+	meter := quota.NewMeter(quota.Config{}, zerolog.Nop())
+	handler := meter.Middleware(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := w.(http.Flusher)
+		assert.True(t, ok, "streaming handlers need http.Flusher")
+		_, _ = w.Write([]byte("event"))
+		w.(http.Flusher).Flush()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(middleware.ContextWithUserID(req.Context(), "user-123"))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	assert.True(t, rr.Flushed)
+	assert.Equal(t, int64(5), meter.Usage("user-123", quota.UnitBytes))
+}
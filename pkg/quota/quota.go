@@ -0,0 +1,281 @@
+// Package quota provides usage metering and quota enforcement for billable units.
+//
+// A Meter counts units (requests, bytes, messages) per principal, enforces
+// configured quotas, and periodically flushes aggregated usage to a pluggable Sink
+// (e.g. a Pub/Sub topic or BigQuery table) for billing.
+//
+// Counters are held in memory, so enforcement is per instance: horizontally scaled
+// services should size quotas accordingly or enforce from an aggregated source.
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Unit identifies a kind of billable usage.
+type Unit string
+
+const (
+	// UnitRequests counts handled requests.
+	UnitRequests Unit = "requests"
+	// UnitBytes counts response bytes written.
+	UnitBytes Unit = "bytes"
+	// UnitMessages counts messages published or consumed.
+	UnitMessages Unit = "messages"
+)
+
+// ErrQuotaExceeded is returned by Consume when a principal has no quota left.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// UsageRecord is an aggregated usage entry delivered to a Sink.
+type UsageRecord struct {
+	Principal   string    `json:"principal"`
+	Unit        Unit      `json:"unit"`
+	Count       int64     `json:"count"`
+	WindowStart time.Time `json:"window_start"`
+	WindowEnd   time.Time `json:"window_end"`
+}
+
+// Sink receives usage records on every flush.
+type Sink interface {
+	Write(ctx context.Context, records []UsageRecord) error
+}
+
+// Provider returns the quota for a principal and unit within the current period.
+// ok is false when the principal has no quota for that unit (i.e. unlimited).
+type Provider interface {
+	Quota(ctx context.Context, principal string, unit Unit) (limit int64, ok bool, err error)
+}
+
+// ProviderFunc adapts an ordinary function to a Provider.
+type ProviderFunc func(ctx context.Context, principal string, unit Unit) (int64, bool, error)
+
+// Quota calls f(ctx, principal, unit).
+func (f ProviderFunc) Quota(ctx context.Context, principal string, unit Unit) (int64, bool, error) {
+	return f(ctx, principal, unit)
+}
+
+// Config holds the configuration for a Meter.
+type Config struct {
+	// Period is the quota window; usage resets at the start of every period. Defaults to 24h.
+	Period time.Duration
+	// FlushInterval is how often usage is written to the Sink. Defaults to one minute.
+	FlushInterval time.Duration
+	// Sink receives usage records. Optional; without it usage is only enforced, not exported.
+	Sink Sink
+	// Provider supplies quotas. Optional; without it nothing is enforced.
+	Provider Provider
+}
+
+// key identifies a counter.
+type key struct {
+	principal string
+	unit      Unit
+}
+
+// Meter counts and enforces usage. It is safe for concurrent use.
+type Meter struct {
+	cfg    Config
+	logger zerolog.Logger
+
+	mu          sync.Mutex
+	periodStart time.Time
+	usage       map[key]int64 // usage within the current quota period
+	pending     map[key]int64 // usage not yet flushed to the sink
+	windowStart time.Time     // start of the pending flush window
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewMeter creates a new Meter. Call Start to begin periodic flushing.
+func NewMeter(cfg Config, logger zerolog.Logger) *Meter {
+	if cfg.Period <= 0 {
+		cfg.Period = 24 * time.Hour
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Minute
+	}
+	now := time.Now()
+	return &Meter{
+		cfg:         cfg,
+		logger:      logger,
+		periodStart: now.Truncate(cfg.Period),
+		usage:       make(map[key]int64),
+		pending:     make(map[key]int64),
+		windowStart: now,
+	}
+}
+
+// Add records n units of usage without enforcing any quota.
+func (m *Meter) Add(principal string, unit Unit, n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollPeriodLocked(time.Now())
+	m.usage[key{principal, unit}] += n
+	m.pending[key{principal, unit}] += n
+}
+
+// Consume records n units of usage if the principal's quota allows it,
+// returning ErrQuotaExceeded otherwise. On success it also returns the units left
+// in the current period, so callers need not query the provider again; ok is false
+// when the principal has no quota for the unit.
+func (m *Meter) Consume(ctx context.Context, principal string, unit Unit, n int64) (remaining int64, ok bool, err error) {
+	limit, limited, err := m.quota(ctx, principal, unit)
+	if err != nil {
+		return 0, false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollPeriodLocked(time.Now())
+	k := key{principal, unit}
+	if limited && m.usage[k]+n > limit {
+		return 0, true, ErrQuotaExceeded
+	}
+	m.usage[k] += n
+	m.pending[k] += n
+	if !limited {
+		return 0, false, nil
+	}
+	return remainingOf(limit, m.usage[k]), true, nil
+}
+
+// Remaining returns how many units the principal may still consume in the current period.
+// ok is false when the principal has no quota for the unit.
+func (m *Meter) Remaining(ctx context.Context, principal string, unit Unit) (remaining int64, ok bool, err error) {
+	limit, limited, err := m.quota(ctx, principal, unit)
+	if err != nil || !limited {
+		return 0, false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollPeriodLocked(time.Now())
+	return remainingOf(limit, m.usage[key{principal, unit}]), true, nil
+}
+
+// Usage returns the units consumed by the principal in the current period.
+func (m *Meter) Usage(principal string, unit Unit) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollPeriodLocked(time.Now())
+	return m.usage[key{principal, unit}]
+}
+
+// Start begins flushing usage to the sink every FlushInterval until Close is called.
+// Each periodic flush is bounded by FlushInterval, so a hung sink cannot stall the loop.
+func (m *Meter) Start() {
+	stop, done := make(chan struct{}), make(chan struct{})
+	m.stop, m.done = stop, done
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(m.cfg.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), m.cfg.FlushInterval)
+				if err := m.Flush(ctx); err != nil {
+					m.logger.Error().Err(err).Msg("Failed to flush usage records")
+				}
+				cancel()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops periodic flushing and performs a final flush. It returns ctx's error
+// if an in-flight periodic flush does not finish before ctx expires.
+func (m *Meter) Close(ctx context.Context) error {
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+		select {
+		case <-m.done:
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for usage flush: %w", ctx.Err())
+		}
+	}
+	return m.Flush(ctx)
+}
+
+// Flush writes all pending usage to the sink. On failure the records are kept
+// and retried on the next flush, so usage is never silently dropped.
+func (m *Meter) Flush(ctx context.Context) error {
+	if m.cfg.Sink == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	now := time.Now()
+	pending := m.pending
+	windowStart := m.windowStart
+	m.pending = make(map[key]int64)
+	m.windowStart = now
+	m.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	records := make([]UsageRecord, 0, len(pending))
+	for k, count := range pending {
+		records = append(records, UsageRecord{
+			Principal:   k.principal,
+			Unit:        k.unit,
+			Count:       count,
+			WindowStart: windowStart,
+			WindowEnd:   now,
+		})
+	}
+
+	if err := m.cfg.Sink.Write(ctx, records); err != nil {
+		m.mu.Lock()
+		for k, count := range pending {
+			m.pending[k] += count
+		}
+		m.windowStart = windowStart
+		m.mu.Unlock()
+		return fmt.Errorf("failed to write %d usage records: %w", len(records), err)
+	}
+
+	m.logger.Debug().Int("records", len(records)).Msg("Flushed usage records")
+	return nil
+}
+
+// quota looks up the configured limit, treating a missing provider as unlimited.
+func (m *Meter) quota(ctx context.Context, principal string, unit Unit) (int64, bool, error) {
+	if m.cfg.Provider == nil {
+		return 0, false, nil
+	}
+	limit, ok, err := m.cfg.Provider.Quota(ctx, principal, unit)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to look up quota for %s: %w", principal, err)
+	}
+	return limit, ok, nil
+}
+
+// remainingOf returns the units left under limit, never less than zero.
+func remainingOf(limit, used int64) int64 {
+	if used > limit {
+		return 0
+	}
+	return limit - used
+}
+
+// rollPeriodLocked resets enforcement counters when a new quota period starts.
+func (m *Meter) rollPeriodLocked(now time.Time) {
+	if start := now.Truncate(m.cfg.Period); start.After(m.periodStart) {
+		m.periodStart = start
+		m.usage = make(map[key]int64)
+	}
+}
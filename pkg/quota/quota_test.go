@@ -0,0 +1,122 @@
+package quota_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/quota"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySink collects flushed records and can be told to fail.
+type memorySink struct {
+	mu      sync.Mutex
+	records []quota.UsageRecord
+	fail    bool
+}
+
+func (s *memorySink) Write(_ context.Context, records []quota.UsageRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("sink unavailable")
+	}
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *memorySink) total(principal string, unit quota.Unit) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var total int64
+	for _, r := range s.records {
+		if r.Principal == principal && r.Unit == unit {
+			total += r.Count
+		}
+	}
+	return total
+}
+
+func TestMeter_ConsumeEnforcesQuota(t *testing.T) {
+	provider := quota.ProviderFunc(func(_ context.Context, principal string, unit quota.Unit) (int64, bool, error) {
+		if principal == "free-user" && unit == quota.UnitMessages {
+			return 5, true, nil
+		}
+		return 0, false, nil
+	})
+	meter := quota.NewMeter(quota.Config{Provider: provider}, zerolog.Nop())
+	ctx := context.Background()
+
+	remaining, limited, err := meter.Consume(ctx, "free-user", quota.UnitMessages, 3)
+	require.NoError(t, err)
+	assert.True(t, limited)
+	assert.Equal(t, int64(2), remaining)
+	_, _, err = meter.Consume(ctx, "free-user", quota.UnitMessages, 2)
+	require.NoError(t, err)
+	_, _, err = meter.Consume(ctx, "free-user", quota.UnitMessages, 1)
+	assert.ErrorIs(t, err, quota.ErrQuotaExceeded)
+	assert.Equal(t, int64(5), meter.Usage("free-user", quota.UnitMessages))
+
+	remaining, limited, err = meter.Remaining(ctx, "free-user", quota.UnitMessages)
+	require.NoError(t, err)
+	assert.True(t, limited)
+	assert.Equal(t, int64(0), remaining)
+
+	// Principals without a quota are unlimited.
+	_, limited, err = meter.Consume(ctx, "paid-user", quota.UnitMessages, 1000)
+	require.NoError(t, err)
+	assert.False(t, limited)
+}
+
+func TestMeter_FlushRetriesOnSinkFailure(t *testing.T) {
+	sink := &memorySink{fail: true}
+	meter := quota.NewMeter(quota.Config{Sink: sink}, zerolog.Nop())
+	ctx := context.Background()
+
+	meter.Add("user-1", quota.UnitBytes, 100)
+	require.Error(t, meter.Flush(ctx))
+
+	// The failed batch is retained and delivered together with new usage.
+	sink.fail = false
+	meter.Add("user-1", quota.UnitBytes, 50)
+	require.NoError(t, meter.Close(ctx))
+	assert.Equal(t, int64(150), sink.total("user-1", quota.UnitBytes))
+
+	// Nothing is pending after a successful flush.
+	require.NoError(t, meter.Flush(ctx))
+	assert.Equal(t, int64(150), sink.total("user-1", quota.UnitBytes))
+}
+
+// blockingSink hangs in Write, ignoring its context, until released.
+type blockingSink struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (s *blockingSink) Write(_ context.Context, _ []quota.UsageRecord) error {
+	s.once.Do(func() { close(s.started) })
+	<-s.release
+	return nil
+}
+
+func TestMeter_CloseHonoursContext(t *testing.T) {
+	sink := &blockingSink{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(sink.release)
+	meter := quota.NewMeter(quota.Config{Sink: sink, FlushInterval: 10 * time.Millisecond}, zerolog.Nop())
+	meter.Add("user-1", quota.UnitRequests, 1)
+	meter.Start()
+	<-sink.started
+
+	// The periodic flush is stuck in the sink; Close must still return by its deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	assert.ErrorIs(t, meter.Close(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(started), time.Second)
+}
@@ -0,0 +1,119 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+// problemDetailsKey marks request contexts whose errors are written as problems.
+type problemDetailsKey struct{}
+
+// ContextWithProblemDetails returns a copy of ctx in which WriteError writes RFC 7807
+// problems instead of the legacy {"error": "..."} body. middleware.UseProblemDetails
+// sets it for every request it wraps.
+func ContextWithProblemDetails(ctx context.Context) context.Context {
+	return context.WithValue(ctx, problemDetailsKey{}, true)
+}
+
+// WriteError writes an error response in the format selected for r: a problem when
+// ContextWithProblemDetails is set, the legacy JSON error otherwise. It is how the
+// library's middlewares write their errors, so one switch covers all of them.
+func WriteError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	if enabled, _ := r.Context().Value(problemDetailsKey{}).(bool); !enabled {
+		WriteJSONError(w, status, message)
+		return
+	}
+	// Legacy messages repeat the status text, e.g. "Unauthorized: Missing nonce";
+	// in a problem that is already the title.
+	detail := strings.TrimPrefix(message, http.StatusText(status)+": ")
+	WriteProblem(w, Problem{Status: status, Detail: detail, Instance: r.URL.Path})
+}
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	// Type is a URI identifying the problem type. Defaults to "about:blank".
+	Type string `json:"type"`
+	// Title is a short summary of the problem type. Defaults to the status text.
+	Title string `json:"title"`
+	// Status is the HTTP status code. Defaults to 500.
+	Status int `json:"status"`
+	// Detail explains this occurrence of the problem.
+	Detail string `json:"detail,omitempty"`
+	// Instance is a URI identifying this occurrence, e.g. the request path.
+	Instance string `json:"instance,omitempty"`
+	// Extensions are additional members serialized alongside the standard ones,
+	// e.g. {"invalid_params": [...]}. They cannot override the standard members.
+	Extensions map[string]any `json:"-"`
+}
+
+// NewProblem returns a Problem of type "about:blank" for status with the given detail.
+func NewProblem(status int, detail string) Problem {
+	return Problem{Status: status, Detail: detail}
+}
+
+// MarshalJSON flattens Extensions into the problem object.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+5)
+	maps.Copy(members, p.Extensions)
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	return json.Marshal(members)
+}
+
+// WriteProblem writes p as application/problem+json with p.Status, filling in the
+// default type, title and status where they are unset.
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		log.Error().Err(err).Msg("Failed to write problem response")
+	}
+}
+
+// Page is a page of results with cursor-based pagination metadata.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// NextCursor fetches the following page; it is omitted on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Total is the number of items across all pages, omitted when unknown.
+	Total *int `json:"total,omitempty"`
+}
+
+// WritePaginated writes a 200 response with items and the pagination metadata, e.g.
+// {"items": [...], "next_cursor": "abc", "total": 42}. Pass an empty nextCursor on
+// the last page and a negative total when the total is unknown.
+func WritePaginated[T any](w http.ResponseWriter, items []T, nextCursor string, total int) {
+	page := Page[T]{Items: items, NextCursor: nextCursor}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if total >= 0 {
+		page.Total = &total
+	}
+	WriteJSON(w, http.StatusOK, page)
+}
@@ -40,3 +40,37 @@ func TestWriteJSONError(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, errorMessage, actualError.Error)
 }
+
+func TestWriteProblem(t *testing.T) {
+	rr := httptest.NewRecorder()
+
+	response.WriteProblem(rr, response.Problem{
+		Status:     http.StatusUnprocessableEntity,
+		Detail:     "name is required",
+		Instance:   "/items",
+		Extensions: map[string]any{"invalid_params": []string{"name"}, "status": "ignored"},
+	})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Equal(t, response.ProblemContentType, rr.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Unprocessable Entity",
+		"status": 422,
+		"detail": "name is required",
+		"instance": "/items",
+		"invalid_params": ["name"]
+	}`, rr.Body.String())
+}
+
+func TestWritePaginated(t *testing.T) {
+	rr := httptest.NewRecorder()
+	response.WritePaginated(rr, []string{"a", "b"}, "cursor-2", 5)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"items":["a","b"],"next_cursor":"cursor-2","total":5}`, rr.Body.String())
+
+	// Last page with an unknown total.
+	rr = httptest.NewRecorder()
+	response.WritePaginated[string](rr, nil, "", -1)
+	assert.JSONEq(t, `{"items":[]}`, rr.Body.String())
+}
@@ -0,0 +1,86 @@
+package signedurl_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/signedurl"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	oldKey = signedurl.Key{ID: "2025-01", Secret: bytes.Repeat([]byte("a"), 32)}
+	newKey = signedurl.Key{ID: "2025-06", Secret: bytes.Repeat([]byte("b"), 32)}
+)
+
+func TestSigner(t *testing.T) {
+	signer, err := signedurl.NewSigner(oldKey)
+	require.NoError(t, err)
+	now := time.Now()
+
+	signed, err := signer.Sign("https://files.example.com/download/report.pdf?user=42", now.Add(time.Hour))
+	require.NoError(t, err)
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+
+	t.Run("Valid URL", func(t *testing.T) {
+		assert.NoError(t, signer.Validate(u, now))
+	})
+
+	t.Run("Expired URL", func(t *testing.T) {
+		assert.ErrorIs(t, signer.Validate(u, now.Add(2*time.Hour)), signedurl.ErrExpired)
+	})
+
+	t.Run("Tampered query", func(t *testing.T) {
+		tampered := *u
+		q := tampered.Query()
+		q.Set("user", "43")
+		tampered.RawQuery = q.Encode()
+		assert.ErrorIs(t, signer.Validate(&tampered, now), signedurl.ErrInvalidSignature)
+	})
+
+	t.Run("Tampered path", func(t *testing.T) {
+		tampered := *u
+		tampered.Path = "/download/secret.pdf"
+		assert.ErrorIs(t, signer.Validate(&tampered, now), signedurl.ErrInvalidSignature)
+	})
+
+	t.Run("Key rotation", func(t *testing.T) {
+		rotated, err := signedurl.NewSigner(newKey, oldKey)
+		require.NoError(t, err)
+		assert.NoError(t, rotated.Validate(u, now), "links signed with the old key remain valid")
+
+		retired, err := signedurl.NewSigner(newKey)
+		require.NoError(t, err)
+		assert.ErrorIs(t, retired.Validate(u, now), signedurl.ErrUnknownKey)
+	})
+
+	t.Run("Short secrets are rejected", func(t *testing.T) {
+		_, err := signedurl.NewSigner(signedurl.Key{ID: "weak", Secret: []byte("short")})
+		assert.Error(t, err)
+	})
+}
+
+func TestSigner_Middleware(t *testing.T) {
+	signer, err := signedurl.NewSigner(newKey)
+	require.NoError(t, err)
+	handler := signer.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	signed, err := signer.Sign("/download/report.pdf", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, signed, nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/download/report.pdf", nil))
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
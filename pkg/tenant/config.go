@@ -0,0 +1,140 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNoTenant is returned when a tenant-scoped lookup is made without a resolved tenant.
+var ErrNoTenant = errors.New("no tenant in context")
+
+// ConfigProvider supplies tenant-specific settings such as limits, feature toggles or branding.
+type ConfigProvider[T any] interface {
+	GetConfig(ctx context.Context, tenantID string) (T, error)
+}
+
+// ConfigLoaderFunc loads a tenant's configuration from its source of truth.
+type ConfigLoaderFunc[T any] func(ctx context.Context, tenantID string) (T, error)
+
+// GetConfig calls f(ctx, tenantID).
+func (f ConfigLoaderFunc[T]) GetConfig(ctx context.Context, tenantID string) (T, error) {
+	return f(ctx, tenantID)
+}
+
+// loadTimeout bounds a shared load, which is detached from the context of the caller
+// that started it.
+const loadTimeout = 30 * time.Second
+
+// cacheEntry is a cached configuration value.
+type cacheEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+// inflight tracks a load in progress so concurrent callers share its result.
+type inflight[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// CachedConfigProvider wraps a ConfigProvider with a TTL cache. Concurrent requests for
+// the same tenant share a single load, and entries can be invalidated explicitly, e.g.
+// when a tenant's settings change. Errors are not cached.
+type CachedConfigProvider[T any] struct {
+	source ConfigProvider[T]
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry[T]
+	loading map[string]*inflight[T]
+	// generation is incremented by every invalidation; a load started in an earlier
+	// generation may have read stale settings, so its result is not cached.
+	generation uint64
+}
+
+// NewCachedConfigProvider creates a cache in front of source. Entries expire after ttl.
+func NewCachedConfigProvider[T any](source ConfigProvider[T], ttl time.Duration) *CachedConfigProvider[T] {
+	return &CachedConfigProvider[T]{
+		source:  source,
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry[T]),
+		loading: make(map[string]*inflight[T]),
+	}
+}
+
+// GetConfig returns the cached configuration for tenantID, loading it if absent or expired.
+// The load is shared by concurrent callers and runs detached from their contexts, so a
+// caller giving up does not fail the others; ctx only bounds how long this caller waits.
+func (p *CachedConfigProvider[T]) GetConfig(ctx context.Context, tenantID string) (T, error) {
+	p.mu.Lock()
+	if entry, ok := p.entries[tenantID]; ok && time.Now().Before(entry.expiresAt) {
+		p.mu.Unlock()
+		return entry.value, nil
+	}
+	call, ok := p.loading[tenantID]
+	if !ok {
+		call = &inflight[T]{done: make(chan struct{})}
+		p.loading[tenantID] = call
+		go p.load(context.WithoutCancel(ctx), tenantID, call, p.generation)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// load fetches the configuration for a shared call and caches it unless the tenant was
+// invalidated in the meantime.
+func (p *CachedConfigProvider[T]) load(ctx context.Context, tenantID string, call *inflight[T], generation uint64) {
+	ctx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+	call.value, call.err = p.source.GetConfig(ctx, tenantID)
+
+	p.mu.Lock()
+	if p.loading[tenantID] == call {
+		delete(p.loading, tenantID)
+	}
+	if call.err == nil && generation == p.generation {
+		p.entries[tenantID] = cacheEntry[T]{value: call.value, expiresAt: time.Now().Add(p.ttl)}
+	}
+	p.mu.Unlock()
+	close(call.done)
+}
+
+// Invalidate drops the cached configuration for a tenant. A load already in progress
+// is not cached, and later lookups start a fresh one.
+func (p *CachedConfigProvider[T]) Invalidate(tenantID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.generation++
+	delete(p.entries, tenantID)
+	delete(p.loading, tenantID)
+}
+
+// InvalidateAll drops every cached configuration.
+func (p *CachedConfigProvider[T]) InvalidateAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.generation++
+	p.entries = make(map[string]cacheEntry[T])
+	p.loading = make(map[string]*inflight[T])
+}
+
+// ConfigFromContext fetches the configuration for the tenant resolved by the middleware.
+// It returns ErrNoTenant if the request has no tenant.
+func ConfigFromContext[T any](ctx context.Context, provider ConfigProvider[T]) (T, error) {
+	t, ok := FromContext(ctx)
+	if !ok {
+		var zero T
+		return zero, ErrNoTenant
+	}
+	return provider.GetConfig(ctx, t.ID)
+}
@@ -0,0 +1,127 @@
+package tenant_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantSettings struct {
+	MaxUsers int
+	Theme    string
+}
+
+func TestCachedConfigProvider(t *testing.T) {
+	var loads atomic.Int32
+	source := tenant.ConfigLoaderFunc[tenantSettings](func(_ context.Context, tenantID string) (tenantSettings, error) {
+		loads.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return tenantSettings{MaxUsers: 10, Theme: tenantID + "-dark"}, nil
+	})
+	provider := tenant.NewCachedConfigProvider[tenantSettings](source, time.Minute)
+	ctx := context.Background()
+
+	t.Run("Concurrent lookups share one load", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				cfg, err := provider.GetConfig(ctx, "acme")
+				assert.NoError(t, err)
+				assert.Equal(t, "acme-dark", cfg.Theme)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), loads.Load())
+	})
+
+	t.Run("Invalidate forces a reload", func(t *testing.T) {
+		provider.Invalidate("acme")
+		_, err := provider.GetConfig(ctx, "acme")
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), loads.Load())
+	})
+
+	t.Run("ConfigFromContext uses the resolved tenant", func(t *testing.T) {
+		_, err := tenant.ConfigFromContext[tenantSettings](ctx, provider)
+		assert.ErrorIs(t, err, tenant.ErrNoTenant)
+
+		tenantCtx := tenant.NewContext(ctx, tenant.Tenant{ID: "globex"})
+		cfg, err := tenant.ConfigFromContext[tenantSettings](tenantCtx, provider)
+		require.NoError(t, err)
+		assert.Equal(t, "globex-dark", cfg.Theme)
+	})
+}
+
+func TestCachedConfigProvider_InflightLoad(t *testing.T) {
+	// newBlockingProvider returns a provider whose first load waits for release.
+	newBlockingProvider := func(loads *atomic.Int32, release chan struct{}) *tenant.CachedConfigProvider[tenantSettings] {
+		source := tenant.ConfigLoaderFunc[tenantSettings](func(ctx context.Context, tenantID string) (tenantSettings, error) {
+			n := loads.Add(1)
+			if n == 1 {
+				select {
+				case <-release:
+				case <-ctx.Done():
+					return tenantSettings{}, ctx.Err()
+				}
+			}
+			return tenantSettings{MaxUsers: int(n)}, nil
+		})
+		return tenant.NewCachedConfigProvider[tenantSettings](source, time.Minute)
+	}
+
+	t.Run("Cancelling the first caller does not fail the others", func(t *testing.T) {
+		var loads atomic.Int32
+		release := make(chan struct{})
+		provider := newBlockingProvider(&loads, release)
+
+		leaderCtx, cancel := context.WithCancel(context.Background())
+		leaderErr := make(chan error, 1)
+		go func() {
+			_, err := provider.GetConfig(leaderCtx, "acme")
+			leaderErr <- err
+		}()
+		require.Eventually(t, func() bool { return loads.Load() == 1 }, time.Second, time.Millisecond)
+
+		followerDone := make(chan tenantSettings, 1)
+		go func() {
+			cfg, err := provider.GetConfig(context.Background(), "acme")
+			assert.NoError(t, err)
+			followerDone <- cfg
+		}()
+
+		cancel()
+		assert.ErrorIs(t, <-leaderErr, context.Canceled)
+		close(release)
+		assert.Equal(t, 1, (<-followerDone).MaxUsers)
+		assert.Equal(t, int32(1), loads.Load())
+	})
+
+	t.Run("A load invalidated while in flight is not cached", func(t *testing.T) {
+		var loads atomic.Int32
+		release := make(chan struct{})
+		provider := newBlockingProvider(&loads, release)
+
+		first := make(chan tenantSettings, 1)
+		go func() {
+			cfg, _ := provider.GetConfig(context.Background(), "acme")
+			first <- cfg
+		}()
+		require.Eventually(t, func() bool { return loads.Load() == 1 }, time.Second, time.Millisecond)
+
+		provider.Invalidate("acme")
+		close(release)
+		assert.Equal(t, 1, (<-first).MaxUsers)
+
+		cfg, err := provider.GetConfig(context.Background(), "acme")
+		require.NoError(t, err)
+		assert.Equal(t, 2, cfg.MaxUsers, "the invalidated load should not have been cached")
+	})
+}
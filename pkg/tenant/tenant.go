@@ -0,0 +1,173 @@
+// Package tenant resolves the tenant a request belongs to and carries it in the request context.
+//
+// Tenants can be identified by subdomain, header, path prefix or a value already placed in
+// the context (e.g. a token claim), tried in a configurable order. An optional Store
+// validates the tenant, so handlers only ever see tenants that exist.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/response"
+)
+
+// ErrUnknownTenant is returned by a Store when the tenant does not exist or is disabled.
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// Tenant is the resolved tenant for a request.
+type Tenant struct {
+	// ID is the tenant identifier.
+	ID string
+	// Source is the name of the Source that resolved the ID, e.g. "header".
+	Source string
+	// Attributes carries optional data supplied by the Store, e.g. plan or region.
+	Attributes map[string]string
+}
+
+// Store validates tenants. Lookup returns ErrUnknownTenant for tenants that must be rejected.
+type Store interface {
+	Lookup(ctx context.Context, id string) (Tenant, error)
+}
+
+// StoreFunc adapts an ordinary function to a Store.
+type StoreFunc func(ctx context.Context, id string) (Tenant, error)
+
+// Lookup calls f(ctx, id).
+func (f StoreFunc) Lookup(ctx context.Context, id string) (Tenant, error) {
+	return f(ctx, id)
+}
+
+// Source extracts a tenant ID from a request.
+type Source struct {
+	// Name identifies the source in the resolved Tenant.
+	Name string
+	// Resolve returns the tenant ID, or false if the request does not carry one.
+	Resolve func(r *http.Request) (string, bool)
+}
+
+// Header resolves the tenant from a request header, e.g. "X-Tenant-ID".
+func Header(name string) Source {
+	return Source{Name: "header", Resolve: func(r *http.Request) (string, bool) {
+		id := r.Header.Get(name)
+		return id, id != ""
+	}}
+}
+
+// Subdomain resolves the tenant from the leftmost label of the host below baseDomain,
+// so with baseDomain "example.com" a request to acme.example.com resolves to "acme".
+func Subdomain(baseDomain string) Source {
+	suffix := "." + strings.TrimPrefix(baseDomain, ".")
+	return Source{Name: "subdomain", Resolve: func(r *http.Request) (string, bool) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		sub, found := strings.CutSuffix(host, suffix)
+		if !found || sub == "" || strings.Contains(sub, ".") {
+			return "", false
+		}
+		return sub, true
+	}}
+}
+
+// PathPrefix resolves the tenant from the path segment following prefix, so with
+// prefix "/tenants/" a request to /tenants/acme/items resolves to "acme".
+// The path itself is left unchanged.
+func PathPrefix(prefix string) Source {
+	return Source{Name: "path", Resolve: func(r *http.Request) (string, bool) {
+		rest, found := strings.CutPrefix(r.URL.Path, prefix)
+		if !found {
+			return "", false
+		}
+		id, _, _ := strings.Cut(rest, "/")
+		return id, id != ""
+	}}
+}
+
+// ContextValue resolves the tenant from a value placed in the context by earlier middleware,
+// such as a claim extracted by the JWT middleware.
+func ContextValue(name string, fn func(ctx context.Context) (string, bool)) Source {
+	return Source{Name: name, Resolve: func(r *http.Request) (string, bool) {
+		return fn(r.Context())
+	}}
+}
+
+// Config holds the configuration for the tenant middleware.
+type Config struct {
+	// Sources are tried in order; the first one that yields an ID wins.
+	Sources []Source
+	// Store validates resolved tenants. Optional.
+	Store Store
+	// Optional lets requests without a tenant through instead of rejecting them with 400.
+	Optional bool
+}
+
+// NewMiddleware resolves the tenant for every request and stores it in the context.
+// Requests without a tenant are rejected with 400 (unless Optional is set), and tenants
+// rejected by the Store with 403.
+func NewMiddleware(cfg Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t, found := resolve(r, cfg.Sources)
+			if !found {
+				if cfg.Optional {
+					next.ServeHTTP(w, r)
+					return
+				}
+				response.WriteError(w, r, http.StatusBadRequest, "Bad Request: Missing tenant")
+				return
+			}
+
+			if cfg.Store != nil {
+				stored, err := cfg.Store.Lookup(r.Context(), t.ID)
+				if errors.Is(err, ErrUnknownTenant) {
+					response.WriteError(w, r, http.StatusForbidden, "Forbidden: Unknown tenant")
+					return
+				}
+				if err != nil {
+					response.WriteError(w, r, http.StatusInternalServerError, "Internal Server Error: Unable to validate tenant")
+					return
+				}
+				stored.ID, stored.Source = t.ID, t.Source
+				t = stored
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), t)))
+		})
+	}
+}
+
+// resolve tries each source in order.
+func resolve(r *http.Request, sources []Source) (Tenant, bool) {
+	for _, s := range sources {
+		if id, ok := s.Resolve(r); ok {
+			return Tenant{ID: id, Source: s.Name}, true
+		}
+	}
+	return Tenant{}, false
+}
+
+// contextKey is a private type to prevent collisions with other context keys.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying t. It is also useful in tests.
+func NewContext(ctx context.Context, t Tenant) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext retrieves the tenant resolved by the middleware.
+func FromContext(ctx context.Context) (Tenant, bool) {
+	t, ok := ctx.Value(contextKey{}).(Tenant)
+	return t, ok
+}
+
+// IDFromRequest returns the resolved tenant ID for a request. It matches the
+// TenantFunc signature of the tenant rate limiting middleware.
+func IDFromRequest(r *http.Request) (string, bool) {
+	t, ok := FromContext(r.Context())
+	return t.ID, ok
+}
@@ -0,0 +1,90 @@
+package tenant_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/tenant"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	var resolved tenant.Tenant
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resolved, _ = tenant.FromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	store := tenant.StoreFunc(func(_ context.Context, id string) (tenant.Tenant, error) {
+		switch id {
+		case "acme", "globex":
+			return tenant.Tenant{Attributes: map[string]string{"plan": "gold"}}, nil
+		case "broken":
+			return tenant.Tenant{}, errors.New("store unavailable")
+		default:
+			return tenant.Tenant{}, tenant.ErrUnknownTenant
+		}
+	})
+
+	// Header wins over subdomain, which wins over the path.
+	handler := tenant.NewMiddleware(tenant.Config{
+		Sources: []tenant.Source{
+			tenant.Header("X-Tenant-ID"),
+			tenant.Subdomain("example.com"),
+			tenant.PathPrefix("/tenants/"),
+		},
+		Store: store,
+	})(testHandler)
+
+	testCases := []struct {
+		name           string
+		host           string
+		path           string
+		header         string
+		expectedCode   int
+		expectedID     string
+		expectedSource string
+	}{
+		{name: "Header", host: "globex.example.com", path: "/", header: "acme", expectedCode: http.StatusOK, expectedID: "acme", expectedSource: "header"},
+		{name: "Subdomain", host: "globex.example.com", path: "/", expectedCode: http.StatusOK, expectedID: "globex", expectedSource: "subdomain"},
+		{name: "Path prefix", host: "api.other.com", path: "/tenants/acme/items", expectedCode: http.StatusOK, expectedID: "acme", expectedSource: "path"},
+		{name: "Missing tenant", host: "api.other.com", path: "/items", expectedCode: http.StatusBadRequest},
+		{name: "Unknown tenant", host: "api.other.com", path: "/", header: "initech", expectedCode: http.StatusForbidden},
+		{name: "Store failure", host: "api.other.com", path: "/", header: "broken", expectedCode: http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolved = tenant.Tenant{}
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			req.Host = tc.host
+			if tc.header != "" {
+				req.Header.Set("X-Tenant-ID", tc.header)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.expectedCode, rr.Code)
+			if tc.expectedCode == http.StatusOK {
+				assert.Equal(t, tc.expectedID, resolved.ID)
+				assert.Equal(t, tc.expectedSource, resolved.Source)
+				assert.Equal(t, "gold", resolved.Attributes["plan"])
+			}
+		})
+	}
+}
+
+func TestIDFromRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	_, ok := tenant.IDFromRequest(req)
+	assert.False(t, ok)
+
+	req = req.WithContext(tenant.NewContext(req.Context(), tenant.Tenant{ID: "acme"}))
+	id, ok := tenant.IDFromRequest(req)
+	assert.True(t, ok)
+	assert.Equal(t, "acme", id)
+}
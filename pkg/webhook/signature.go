@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader carries the payload signatures, e.g. "t=1700000000,v1=ab12…,v1=cd34…".
+const SignatureHeader = "X-Webhook-Signature"
+
+// ErrInvalidSignature is returned by VerifySignature when no signature matches.
+var ErrInvalidSignature = errors.New("webhook: invalid signature")
+
+// Sign builds the signature header value for body, with one v1 entry per secret.
+// Signing with several secrets lets receivers rotate their secret without downtime.
+func Sign(secrets [][]byte, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	parts := []string{"t=" + ts}
+	for _, secret := range secrets {
+		parts = append(parts, "v1="+computeSignature(secret, ts, body))
+	}
+	return strings.Join(parts, ",")
+}
+
+// VerifySignature checks a signature header produced by Sign against secret, rejecting
+// timestamps older than tolerance to limit replays. Receivers built on this library
+// should call it before trusting a webhook payload.
+func VerifySignature(secret []byte, header string, body []byte, tolerance time.Duration) error {
+	var ts string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing timestamp", ErrInvalidSignature)
+	}
+	if tolerance > 0 && time.Since(time.Unix(unix, 0)) > tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+
+	expected := computeSignature(secret, ts, body)
+	for _, sig := range signatures {
+		if hmac.Equal([]byte(sig), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// computeSignature is the hex HMAC-SHA256 of "<timestamp>.<body>".
+func computeSignature(secret []byte, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,27 @@
+package webhook_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	oldSecret := []byte("old-secret")
+	newSecret := []byte("new-secret")
+	body := []byte(`{"id":"evt-1"}`)
+
+	header := webhook.Sign([][]byte{newSecret, oldSecret}, time.Now(), body)
+
+	// Receivers on either side of a rotation can verify.
+	assert.NoError(t, webhook.VerifySignature(newSecret, header, body, time.Minute))
+	assert.NoError(t, webhook.VerifySignature(oldSecret, header, body, time.Minute))
+
+	assert.ErrorIs(t, webhook.VerifySignature([]byte("other"), header, body, time.Minute), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.VerifySignature(newSecret, header, []byte(`{"id":"evt-2"}`), time.Minute), webhook.ErrInvalidSignature)
+
+	stale := webhook.Sign([][]byte{newSecret}, time.Now().Add(-time.Hour), body)
+	assert.ErrorIs(t, webhook.VerifySignature(newSecret, stale, body, time.Minute), webhook.ErrInvalidSignature)
+}
@@ -0,0 +1,346 @@
+// Package webhook provides an outbound webhook dispatcher.
+//
+// Endpoints subscribe to event types. Every delivery is signed (see Sign), retried with
+// exponential backoff on network errors, 429 and 5xx responses, and handed to a dead-letter
+// sink once it cannot be delivered. Delivery outcomes are exported as Prometheus metrics.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+var (
+	// ErrQueueFull is returned by Dispatch when the delivery queue is at capacity.
+	ErrQueueFull = errors.New("webhook: delivery queue is full")
+	// ErrClosed is returned by Dispatch after Close has been called.
+	ErrClosed = errors.New("webhook: dispatcher is closed")
+)
+
+// Endpoint is a subscriber to one or more event types.
+type Endpoint struct {
+	ID  string
+	URL string
+	// EventTypes lists the subscribed event types; "*" subscribes to all events.
+	EventTypes []string
+	// Secrets sign the payload. During rotation, list the new secret first and keep
+	// the old one until the receiver has switched over.
+	Secrets [][]byte
+}
+
+// Event is a notification to be delivered to subscribed endpoints.
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Delivery is a single event destined for a single endpoint.
+type Delivery struct {
+	Event    Event
+	Endpoint Endpoint
+	Attempts int
+}
+
+// DeadLetterSink receives deliveries that exhausted their retries or were rejected outright.
+type DeadLetterSink interface {
+	DeadLetter(ctx context.Context, delivery Delivery, cause error) error
+}
+
+// Config holds the configuration for a Dispatcher.
+type Config struct {
+	// Client performs the deliveries. Defaults to a client with a 10s timeout.
+	Client *http.Client
+	// MaxAttempts is the number of delivery attempts before dead-lettering. Defaults to 5.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry; it doubles per attempt. Defaults to 1s.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the retry delay. Defaults to 1 minute.
+	MaxBackoff time.Duration
+	// Workers is the number of concurrent deliveries. Defaults to 4.
+	Workers int
+	// QueueSize bounds the number of pending deliveries. Defaults to 1000.
+	QueueSize int
+	// DeadLetter receives undeliverable deliveries. Optional; they are logged regardless.
+	DeadLetter DeadLetterSink
+	// Registerer is where delivery metrics are registered. Defaults to the global registry.
+	Registerer prometheus.Registerer
+}
+
+// Dispatcher delivers events to registered endpoints in the background.
+type Dispatcher struct {
+	cfg    Config
+	logger zerolog.Logger
+
+	mu        sync.RWMutex
+	endpoints map[string]Endpoint
+	started   bool
+	closed    bool
+
+	// enqueueMu serializes Dispatch calls so capacity checked for an event is still free
+	// when its deliveries are queued.
+	enqueueMu sync.Mutex
+	queue     chan Delivery
+	// stopCtx is cancelled when Close gives up waiting; in-flight requests and
+	// dead-letter writes are aborted and remaining deliveries are dead-lettered.
+	stopCtx context.Context
+	stop    context.CancelFunc
+	wg      sync.WaitGroup
+
+	deliveries *prometheus.CounterVec
+	attempts   *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+}
+
+// NewDispatcher creates a Dispatcher. Call Start to begin delivering.
+func NewDispatcher(cfg Config, logger zerolog.Logger) *Dispatcher {
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = time.Minute
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+
+	stopCtx, stop := context.WithCancel(context.Background())
+	return &Dispatcher{
+		cfg:       cfg,
+		logger:    logger,
+		endpoints: make(map[string]Endpoint),
+		queue:     make(chan Delivery, cfg.QueueSize),
+		stopCtx:   stopCtx,
+		stop:      stop,
+		deliveries: metrics.Register(cfg.Registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "webhook_deliveries_total",
+			Help: "Webhook deliveries by final outcome.",
+		}, []string{"event_type", "outcome"})),
+		attempts: metrics.Register(cfg.Registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "webhook_delivery_attempts_total",
+			Help: "Individual webhook delivery attempts.",
+		}, []string{"event_type"})),
+		duration: metrics.Register(cfg.Registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "webhook_delivery_duration_seconds",
+			Help:    "Duration of individual webhook delivery attempts.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"event_type"})),
+	}
+}
+
+// Register adds or replaces an endpoint.
+func (d *Dispatcher) Register(ep Endpoint) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.endpoints[ep.ID] = ep
+}
+
+// Unregister removes an endpoint. Deliveries already queued are still attempted.
+func (d *Dispatcher) Unregister(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.endpoints, id)
+}
+
+// Dispatch queues event for every endpoint subscribed to its type.
+// It never blocks: if the queue cannot take the deliveries for all subscribed endpoints,
+// none are queued and ErrQueueFull is returned, so the caller can safely retry.
+func (d *Dispatcher) Dispatch(_ context.Context, event Event) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
+
+	var deliveries []Delivery
+	for _, ep := range d.endpoints {
+		if subscribed(ep, event.Type) {
+			deliveries = append(deliveries, Delivery{Event: event, Endpoint: ep})
+		}
+	}
+
+	// Workers only ever free capacity, so with enqueueMu held the sends below cannot block.
+	d.enqueueMu.Lock()
+	defer d.enqueueMu.Unlock()
+	if cap(d.queue)-len(d.queue) < len(deliveries) {
+		return ErrQueueFull
+	}
+	for _, delivery := range deliveries {
+		d.queue <- delivery
+	}
+	return nil
+}
+
+// Start launches the delivery workers.
+func (d *Dispatcher) Start() {
+	d.mu.Lock()
+	d.started = true
+	d.mu.Unlock()
+	for i := 0; i < d.cfg.Workers; i++ {
+		d.wg.Add(1)
+		go d.worker()
+	}
+}
+
+// Close stops accepting events and waits for queued deliveries to finish or ctx to expire.
+// Once ctx expires, in-flight requests are aborted and every delivery that is still queued
+// or waiting for a retry is dead-lettered rather than attempted. Those dead-letter writes
+// get an already-cancelled context, so Close returns promptly; a sink that must keep them
+// should record them without waiting on the context.
+// If Start was never called, queued deliveries are dead-lettered within ctx.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	started := d.started
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	if !started {
+		for delivery := range d.queue {
+			d.deadLetter(ctx, delivery, errors.New("dispatcher closed before it was started"))
+		}
+		return ctx.Err()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		d.stop()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// worker delivers queued deliveries until the queue is closed and drained.
+func (d *Dispatcher) worker() {
+	defer d.wg.Done()
+	for delivery := range d.queue {
+		if d.stopCtx.Err() != nil {
+			d.deadLetter(d.stopCtx, delivery, errors.New("dispatcher stopped before delivery"))
+			continue
+		}
+		d.deliver(delivery)
+	}
+}
+
+// deliver attempts a delivery with retries, dead-lettering it on permanent failure.
+func (d *Dispatcher) deliver(delivery Delivery) {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		d.deadLetter(d.stopCtx, delivery, fmt.Errorf("failed to encode event: %w", err))
+		return
+	}
+
+	backoff := d.cfg.InitialBackoff
+	for {
+		delivery.Attempts++
+		retryable, err := d.attempt(delivery, body)
+		if err == nil {
+			d.deliveries.WithLabelValues(delivery.Event.Type, "success").Inc()
+			return
+		}
+		if !retryable || delivery.Attempts >= d.cfg.MaxAttempts {
+			d.deadLetter(d.stopCtx, delivery, err)
+			return
+		}
+
+		d.logger.Warn().Err(err).Str("endpoint", delivery.Endpoint.ID).Int("attempt", delivery.Attempts).
+			Msg("Webhook delivery failed, retrying")
+		// Jitter keeps many failing deliveries from retrying in lockstep.
+		wait := time.Duration(rand.Int64N(int64(backoff))) + backoff/2
+		select {
+		case <-time.After(wait):
+		case <-d.stopCtx.Done():
+			d.deadLetter(d.stopCtx, delivery, fmt.Errorf("dispatcher stopped before retry: %w", err))
+			return
+		}
+		backoff = min(backoff*2, d.cfg.MaxBackoff)
+	}
+}
+
+// attempt performs one HTTP delivery. It reports whether a failure is worth retrying.
+func (d *Dispatcher) attempt(delivery Delivery, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(d.stopCtx, http.MethodPost, delivery.Endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("invalid endpoint URL: %w", err)
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-ID", delivery.Event.ID)
+	req.Header.Set("X-Webhook-Event", delivery.Event.Type)
+	req.Header.Set(SignatureHeader, Sign(delivery.Endpoint.Secrets, now, body))
+
+	d.attempts.WithLabelValues(delivery.Event.Type).Inc()
+	resp, err := d.cfg.Client.Do(req)
+	d.duration.WithLabelValues(delivery.Event.Type).Observe(time.Since(now).Seconds())
+	if err != nil {
+		return true, fmt.Errorf("delivery request failed: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("endpoint rejected delivery with status %d", resp.StatusCode)
+	}
+}
+
+// deadLetter records a delivery that will not be retried. The sink write is bounded by
+// ctx and by a 10s timeout.
+func (d *Dispatcher) deadLetter(ctx context.Context, delivery Delivery, cause error) {
+	d.deliveries.WithLabelValues(delivery.Event.Type, "dead_lettered").Inc()
+	d.logger.Error().Err(cause).Str("endpoint", delivery.Endpoint.ID).Str("event_id", delivery.Event.ID).
+		Int("attempts", delivery.Attempts).Msg("Webhook delivery dead-lettered")
+
+	if d.cfg.DeadLetter == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := d.cfg.DeadLetter.DeadLetter(ctx, delivery, cause); err != nil {
+		d.logger.Error().Err(err).Str("event_id", delivery.Event.ID).Msg("Failed to write dead letter")
+	}
+}
+
+// subscribed reports whether ep receives events of the given type.
+func subscribed(ep Endpoint, eventType string) bool {
+	for _, t := range ep.EventTypes {
+		if t == "*" || t == eventType {
+			return true
+		}
+	}
+	return false
+}
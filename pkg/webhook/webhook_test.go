@@ -0,0 +1,196 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/illmade-knight/go-microservice-base/pkg/webhook"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryDeadLetters records dead-lettered deliveries.
+type memoryDeadLetters struct {
+	mu         sync.Mutex
+	deliveries []webhook.Delivery
+}
+
+func (m *memoryDeadLetters) DeadLetter(_ context.Context, d webhook.Delivery, _ error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deliveries = append(m.deliveries, d)
+	return nil
+}
+
+func (m *memoryDeadLetters) all() []webhook.Delivery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]webhook.Delivery(nil), m.deliveries...)
+}
+
+func TestDispatcher(t *testing.T) {
+	secret := []byte("endpoint-secret")
+
+	// A receiver that fails twice before accepting, verifying the signature each time.
+	var calls atomic.Int32
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.VerifySignature(secret, r.Header.Get(webhook.SignatureHeader), body, time.Minute); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer flaky.Close()
+
+	// A receiver that permanently rejects deliveries.
+	rejecting := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer rejecting.Close()
+
+	deadLetters := &memoryDeadLetters{}
+	dispatcher := webhook.NewDispatcher(webhook.Config{
+		InitialBackoff: 5 * time.Millisecond,
+		MaxAttempts:    5,
+		DeadLetter:     deadLetters,
+		Registerer:     prometheus.NewRegistry(),
+	}, zerolog.Nop())
+	dispatcher.Register(webhook.Endpoint{ID: "flaky", URL: flaky.URL, EventTypes: []string{"order.created"}, Secrets: [][]byte{secret}})
+	dispatcher.Register(webhook.Endpoint{ID: "gone", URL: rejecting.URL, EventTypes: []string{"*"}, Secrets: [][]byte{secret}})
+	dispatcher.Start()
+
+	ctx := context.Background()
+	require.NoError(t, dispatcher.Dispatch(ctx, webhook.Event{
+		ID: "evt-1", Type: "order.created", OccurredAt: time.Now(), Data: json.RawMessage(`{"order_id":"42"}`),
+	}))
+
+	closeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	require.NoError(t, dispatcher.Close(closeCtx))
+
+	assert.Equal(t, int32(3), calls.Load(), "flaky endpoint should succeed on the third attempt")
+
+	dead := deadLetters.all()
+	require.Len(t, dead, 1)
+	assert.Equal(t, "gone", dead[0].Endpoint.ID)
+	assert.Equal(t, 1, dead[0].Attempts, "4xx responses are not retried")
+
+	assert.ErrorIs(t, dispatcher.Dispatch(ctx, webhook.Event{Type: "order.created"}), webhook.ErrClosed)
+}
+
+func TestDispatcher_CloseDeadLettersAfterDeadline(t *testing.T) {
+	release := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer hanging.Close()
+	defer close(release)
+
+	deadLetters := &memoryDeadLetters{}
+	dispatcher := webhook.NewDispatcher(webhook.Config{
+		Client:     &http.Client{Timeout: time.Minute},
+		Workers:    1,
+		DeadLetter: deadLetters,
+		Registerer: prometheus.NewRegistry(),
+	}, zerolog.Nop())
+	dispatcher.Register(webhook.Endpoint{ID: "hanging", URL: hanging.URL, EventTypes: []string{"*"}})
+	dispatcher.Start()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, dispatcher.Dispatch(context.Background(), webhook.Event{Type: "order.created"}))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.ErrorIs(t, dispatcher.Close(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 2*time.Second, "Close must not wait for the remaining deliveries")
+	assert.Len(t, deadLetters.all(), 10, "in-flight and queued deliveries are dead-lettered")
+}
+
+// slowDeadLetters blocks every write until its context is done.
+type slowDeadLetters struct {
+	calls atomic.Int32
+}
+
+func (s *slowDeadLetters) DeadLetter(ctx context.Context, _ webhook.Delivery, _ error) error {
+	s.calls.Add(1)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestDispatcher_CloseBoundsDeadLetterWrites(t *testing.T) {
+	release := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer hanging.Close()
+	defer close(release)
+
+	deadLetters := &slowDeadLetters{}
+	dispatcher := webhook.NewDispatcher(webhook.Config{
+		Client:     &http.Client{Timeout: time.Minute},
+		Workers:    1,
+		DeadLetter: deadLetters,
+		Registerer: prometheus.NewRegistry(),
+	}, zerolog.Nop())
+	dispatcher.Register(webhook.Endpoint{ID: "hanging", URL: hanging.URL, EventTypes: []string{"*"}})
+	dispatcher.Start()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, dispatcher.Dispatch(context.Background(), webhook.Event{Type: "order.created"}))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.ErrorIs(t, dispatcher.Close(ctx), context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 2*time.Second, "a slow dead-letter sink must not hold Close past its deadline")
+	assert.Equal(t, int32(10), deadLetters.calls.Load())
+}
+
+func TestDispatcher_CloseWithoutStartDeadLetters(t *testing.T) {
+	deadLetters := &memoryDeadLetters{}
+	dispatcher := webhook.NewDispatcher(webhook.Config{DeadLetter: deadLetters, Registerer: prometheus.NewRegistry()}, zerolog.Nop())
+	dispatcher.Register(webhook.Endpoint{ID: "a", URL: "http://127.0.0.1:1", EventTypes: []string{"*"}})
+	require.NoError(t, dispatcher.Dispatch(context.Background(), webhook.Event{ID: "evt-1"}))
+
+	require.NoError(t, dispatcher.Close(context.Background()))
+	dead := deadLetters.all()
+	require.Len(t, dead, 1)
+	assert.Equal(t, "evt-1", dead[0].Event.ID)
+}
+
+func TestDispatcher_DispatchIsAllOrNothing(t *testing.T) {
+	dispatcher := webhook.NewDispatcher(webhook.Config{QueueSize: 3, Registerer: prometheus.NewRegistry()}, zerolog.Nop())
+	for _, id := range []string{"a", "b"} {
+		dispatcher.Register(webhook.Endpoint{ID: id, URL: "http://127.0.0.1:1", EventTypes: []string{"*"}})
+	}
+
+	// Not started, so nothing drains the queue: the first event takes 2 of 3 slots.
+	require.NoError(t, dispatcher.Dispatch(context.Background(), webhook.Event{ID: "1"}))
+	assert.ErrorIs(t, dispatcher.Dispatch(context.Background(), webhook.Event{ID: "2"}), webhook.ErrQueueFull)
+
+	// The rejected event queued nothing, so a smaller one still fits.
+	dispatcher.Unregister("b")
+	assert.NoError(t, dispatcher.Dispatch(context.Background(), webhook.Event{ID: "3"}))
+	assert.ErrorIs(t, dispatcher.Dispatch(context.Background(), webhook.Event{ID: "4"}), webhook.ErrQueueFull)
+}